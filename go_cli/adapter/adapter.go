@@ -0,0 +1,75 @@
+// Package adapter loads fine-tuned adapter runs from the trainer's output
+// directory and compares them structurally.
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/safetensors"
+)
+
+// ConfigFile is the PEFT adapter configuration written next to the weights.
+const ConfigFile = "adapter_config.json"
+
+// weightFiles lists the weight files looked for in a run directory, in
+// order of preference.
+var weightFiles = []string{"adapter_model.safetensors", "model.safetensors"}
+
+// Run is a single adapter output directory.
+type Run struct {
+	Dir     string
+	Config  map[string]any
+	Weights *safetensors.Header
+}
+
+// Load reads the adapter config and safetensors header of the run in dir.
+// A missing config is tolerated; missing weights are not.
+func Load(dir string) (*Run, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	r := &Run{Dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &r.Config); err != nil {
+			return nil, fmt.Errorf("%s: %w", ConfigFile, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	path, err := findWeights(dir)
+	if err != nil {
+		return nil, err
+	}
+	if r.Weights, err = safetensors.ReadHeader(path); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func findWeights(dir string) (string, error) {
+	for _, name := range weightFiles {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.safetensors"))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no .safetensors weights in %s", dir)
+	}
+	sort.Strings(matches)
+	return matches[0], nil
+}
@@ -0,0 +1,180 @@
+package adapter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/safetensors"
+)
+
+// KeyConfigFields are the adapter settings that change the structure of the
+// weights; they are reported first in a diff.
+var KeyConfigFields = []string{"r", "lora_alpha", "target_modules"}
+
+// ConfigChange is a config key whose value differs between two runs. A or B
+// is nil when the key is missing from that run.
+type ConfigChange struct {
+	Key string `json:"key"`
+	A   any    `json:"a"`
+	B   any    `json:"b"`
+}
+
+// TensorChange describes a tensor present in both runs with a different
+// shape or dtype.
+type TensorChange struct {
+	Name   string  `json:"name"`
+	DTypeA string  `json:"dtype_a"`
+	DTypeB string  `json:"dtype_b"`
+	ShapeA []int64 `json:"shape_a"`
+	ShapeB []int64 `json:"shape_b"`
+}
+
+// TensorStats compares the values of a tensor present in both runs with the
+// same shape. RelativeDelta is 0 when the tensor in run A is all zeros, and
+// Cosine is 0 when either tensor is.
+type TensorStats struct {
+	Name          string  `json:"name"`
+	Shape         []int64 `json:"shape"`
+	NormA         float64 `json:"l2_norm_a"`
+	NormB         float64 `json:"l2_norm_b"`
+	DeltaNorm     float64 `json:"l2_norm_delta"`
+	RelativeDelta float64 `json:"relative_delta"`
+	MaxAbsDelta   float64 `json:"max_abs_delta"`
+	Cosine        float64 `json:"cosine_similarity"`
+}
+
+// Diff is the structural comparison of two adapter runs.
+type Diff struct {
+	RunA     string         `json:"run_a"`
+	RunB     string         `json:"run_b"`
+	Config   []ConfigChange `json:"config_changes"`
+	Added    []string       `json:"added_tensors"`
+	Removed  []string       `json:"removed_tensors"`
+	Reshaped []TensorChange `json:"reshaped_tensors"`
+	Matched  []TensorStats  `json:"matched_tensors"`
+}
+
+// Compare diffs the configs and weights of two runs. Tensor values are only
+// read for tensors whose name, shape and dtype match.
+func Compare(a, b *Run) (*Diff, error) {
+	d := &Diff{
+		RunA:     a.Dir,
+		RunB:     b.Dir,
+		Config:   diffConfig(a.Config, b.Config),
+		Added:    []string{},
+		Removed:  []string{},
+		Reshaped: []TensorChange{},
+		Matched:  []TensorStats{},
+	}
+
+	for _, tb := range b.Weights.Tensors {
+		if _, ok := a.Weights.Tensor(tb.Name); !ok {
+			d.Added = append(d.Added, tb.Name)
+		}
+	}
+	for _, ta := range a.Weights.Tensors {
+		tb, ok := b.Weights.Tensor(ta.Name)
+		if !ok {
+			d.Removed = append(d.Removed, ta.Name)
+			continue
+		}
+		if ta.DType != tb.DType || !slices.Equal(ta.Shape, tb.Shape) {
+			d.Reshaped = append(d.Reshaped, TensorChange{
+				Name: ta.Name, DTypeA: ta.DType, DTypeB: tb.DType, ShapeA: ta.Shape, ShapeB: tb.Shape,
+			})
+			continue
+		}
+		stats, err := compareTensor(a.Weights, b.Weights, ta, tb)
+		if err != nil {
+			return nil, err
+		}
+		d.Matched = append(d.Matched, stats)
+	}
+	return d, nil
+}
+
+func compareTensor(ha, hb *safetensors.Header, ta, tb safetensors.Tensor) (TensorStats, error) {
+	va, err := safetensors.ReadFileValues(ha, ta)
+	if err != nil {
+		return TensorStats{}, err
+	}
+	vb, err := safetensors.ReadFileValues(hb, tb)
+	if err != nil {
+		return TensorStats{}, err
+	}
+	if len(va) != len(vb) {
+		return TensorStats{}, fmt.Errorf("tensor %q: %d values in A, %d in B", ta.Name, len(va), len(vb))
+	}
+
+	s := TensorStats{Name: ta.Name, Shape: ta.Shape}
+	var sumA, sumB, sumD, dot float64
+	for i := range va {
+		delta := vb[i] - va[i]
+		sumA += va[i] * va[i]
+		sumB += vb[i] * vb[i]
+		sumD += delta * delta
+		dot += va[i] * vb[i]
+		s.MaxAbsDelta = math.Max(s.MaxAbsDelta, math.Abs(delta))
+	}
+	s.NormA, s.NormB, s.DeltaNorm = math.Sqrt(sumA), math.Sqrt(sumB), math.Sqrt(sumD)
+	if s.NormA > 0 {
+		s.RelativeDelta = s.DeltaNorm / s.NormA
+	}
+	if s.NormA > 0 && s.NormB > 0 {
+		s.Cosine = dot / (s.NormA * s.NormB)
+	}
+	return s, nil
+}
+
+func diffConfig(a, b map[string]any) []ConfigChange {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	var rest []string
+	for k := range keys {
+		if !slices.Contains(KeyConfigFields, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	changes := []ConfigChange{}
+	for _, k := range append(slices.Clone(KeyConfigFields), rest...) {
+		va, okA := a[k]
+		vb, okB := b[k]
+		if !okA && !okB {
+			continue
+		}
+		if okA && okB && reflect.DeepEqual(normalize(va), normalize(vb)) {
+			continue
+		}
+		changes = append(changes, ConfigChange{Key: k, A: va, B: vb})
+	}
+	return changes
+}
+
+// normalize sorts string lists so that target_modules given in a different
+// order compare equal.
+func normalize(v any) any {
+	list, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	strs := make([]string, 0, len(list))
+	for _, e := range list {
+		s, ok := e.(string)
+		if !ok {
+			return v
+		}
+		strs = append(strs, s)
+	}
+	sort.Strings(strs)
+	return strs
+}
@@ -0,0 +1,129 @@
+package adapter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// tensor is an F32 tensor of a synthetic run.
+type tensor struct {
+	shape  []int64
+	values []float32
+}
+
+// writeRun writes an adapter run with the given config and F32 tensors to
+// a new directory and loads it.
+func writeRun(t *testing.T, config string, tensors map[string]tensor) *Run {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(tensors))
+	for name := range tensors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	header := map[string]any{}
+	var data []byte
+	for _, name := range names {
+		tt := tensors[name]
+		begin := len(data)
+		for _, v := range tt.values {
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(v))
+		}
+		header[name] = map[string]any{"dtype": "F32", "shape": tt.shape, "data_offsets": []int{begin, len(data)}}
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := binary.LittleEndian.AppendUint64(nil, uint64(len(h)))
+	file = append(append(file, h...), data...)
+	if err := os.WriteFile(filepath.Join(dir, "adapter_model.safetensors"), file, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+func TestCompare(t *testing.T) {
+	a := writeRun(t, `{"r": 8, "lora_alpha": 16, "target_modules": ["q_proj", "v_proj"]}`, map[string]tensor{
+		"same":       {[]int64{2}, []float32{3, 4}},
+		"scaled":     {[]int64{2}, []float32{3, 4}},
+		"orthogonal": {[]int64{2}, []float32{1, 0}},
+		"zero_a":     {[]int64{2}, []float32{0, 0}},
+		"zero_both":  {[]int64{2}, []float32{0, 0}},
+		"reshaped":   {[]int64{2}, []float32{1, 1}},
+		"removed":    {[]int64{1}, []float32{1}},
+	})
+	b := writeRun(t, `{"r": 16, "lora_alpha": 16, "target_modules": ["v_proj", "q_proj"], "lora_dropout": 0.1}`, map[string]tensor{
+		"same":       {[]int64{2}, []float32{3, 4}},
+		"scaled":     {[]int64{2}, []float32{6, 8}},
+		"orthogonal": {[]int64{2}, []float32{0, 2}},
+		"zero_a":     {[]int64{2}, []float32{1, 1}},
+		"zero_both":  {[]int64{2}, []float32{0, 0}},
+		"reshaped":   {[]int64{1, 2}, []float32{1, 1}},
+		"added":      {[]int64{1}, []float32{1}},
+	})
+
+	d, err := Compare(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// target_modules only changed order.
+	var keys []string
+	for _, c := range d.Config {
+		keys = append(keys, c.Key)
+	}
+	if !slices.Equal(keys, []string{"r", "lora_dropout"}) {
+		t.Errorf("config changes = %+v", d.Config)
+	}
+	if !slices.Equal(d.Added, []string{"added"}) || !slices.Equal(d.Removed, []string{"removed"}) {
+		t.Errorf("added = %v, removed = %v", d.Added, d.Removed)
+	}
+	if len(d.Reshaped) != 1 || d.Reshaped[0].Name != "reshaped" || !slices.Equal(d.Reshaped[0].ShapeB, []int64{1, 2}) {
+		t.Errorf("reshaped = %+v", d.Reshaped)
+	}
+
+	want := map[string]TensorStats{
+		"same":       {NormA: 5, NormB: 5, Cosine: 1},
+		"scaled":     {NormA: 5, NormB: 10, DeltaNorm: 5, RelativeDelta: 1, MaxAbsDelta: 4, Cosine: 1},
+		"orthogonal": {NormA: 1, NormB: 2, DeltaNorm: math.Sqrt(5), RelativeDelta: math.Sqrt(5), MaxAbsDelta: 2},
+		"zero_a":     {NormB: math.Sqrt2, DeltaNorm: math.Sqrt2, MaxAbsDelta: 1},
+		"zero_both":  {},
+	}
+	if len(d.Matched) != len(want) {
+		t.Fatalf("matched = %+v", d.Matched)
+	}
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	for _, got := range d.Matched {
+		w, ok := want[got.Name]
+		if !ok {
+			t.Errorf("unexpected match %q", got.Name)
+			continue
+		}
+		if math.IsNaN(got.Cosine) || math.IsNaN(got.RelativeDelta) {
+			t.Errorf("%s: NaN in %+v", got.Name, got)
+		}
+		if !near(got.NormA, w.NormA) || !near(got.NormB, w.NormB) || !near(got.DeltaNorm, w.DeltaNorm) ||
+			!near(got.RelativeDelta, w.RelativeDelta) || !near(got.MaxAbsDelta, w.MaxAbsDelta) || !near(got.Cosine, w.Cosine) {
+			t.Errorf("%s = %+v, want %+v", got.Name, got, w)
+		}
+	}
+
+	// The diff is written out as JSON, which has no NaN.
+	if _, err := json.Marshal(d); err != nil {
+		t.Errorf("marshalling diff: %v", err)
+	}
+}
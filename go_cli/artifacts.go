@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/safetensors"
+)
+
+// inspectPageSize is the number of header lines shown at once in the
+// artifact inspector.
+const inspectPageSize = 20
+
+// --- Artifact Browser ---
+func listArtifacts() []string {
+	files, err := findSafetensors(outputRoot)
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+func inspectArtifact(path string) []string {
+	h, err := safetensors.ReadHeader(path)
+	if err != nil {
+		return []string{"Error: " + err.Error()}
+	}
+	return strings.Split(strings.TrimRight(formatHeader(h), "\n"), "\n")
+}
+
+// diffArtifacts compares the runs containing two adapter weight files.
+// Files from the same run directory would compare the run with itself.
+func diffArtifacts(a, b string) []string {
+	if filepath.Dir(a) == filepath.Dir(b) {
+		return []string{"Error: both files are in " + filepath.Dir(a) + "; mark weights from two different runs"}
+	}
+	d, err := diffRuns(filepath.Dir(a), filepath.Dir(b))
+	if err != nil {
+		return []string{"Error: " + err.Error()}
+	}
+	return strings.Split(strings.TrimRight(formatDiff(d), "\n"), "\n")
+}
+
+func (m model) markedArtifacts() []string {
+	var marked []string
+	for _, f := range m.artifactFiles {
+		if m.artifactMarks[f] {
+			marked = append(marked, f)
+		}
+	}
+	return marked
+}
+
+func (m model) artifactsView() string {
+	out := headerStyle.Render("Artifacts") + "\n\n"
+	if len(m.artifactFiles) == 0 {
+		out += fmt.Sprintf("No .safetensors files found in %s/\n", outputRoot)
+	}
+	for i, f := range m.artifactFiles {
+		mark := "[ ] "
+		if m.artifactMarks[f] {
+			mark = "[x] "
+		}
+		if i == m.menuIdx {
+			out += selectedStyle.Render("> "+mark+f) + "\n"
+		} else {
+			out += "  " + mark + f + "\n"
+		}
+	}
+	return out + "\n[Enter] Inspect  [Space] Mark  [d] Diff marked  [r] Refresh  [ESC] Back"
+}
+
+func (m model) inspectView() string {
+	end := m.inspectScroll + inspectPageSize
+	if end > len(m.inspectLines) {
+		end = len(m.inspectLines)
+	}
+	out := headerStyle.Render(m.inspectTitle) + "\n\n"
+	out += strings.Join(m.inspectLines[m.inspectScroll:end], "\n")
+	return out + fmt.Sprintf("\n\n[%d-%d of %d]  [↑/↓] Scroll  [ESC] Back", m.inspectScroll+1, end, len(m.inspectLines))
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffArtifactsSameRun(t *testing.T) {
+	lines := diffArtifacts("nexa_output/run-a/adapter_model.safetensors", "nexa_output/run-a/./model.safetensors")
+	if len(lines) != 1 || !strings.Contains(lines[0], "two different runs") {
+		t.Errorf("diff of one run = %q, want an error", lines)
+	}
+}
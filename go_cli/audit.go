@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/audit"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hub"
+)
+
+// auditLog records token changes, job submissions and pushes. Unlike
+// Tune.log it is hash-chained and never rotated or archived.
+var auditLog = audit.Open(audit.DefaultPath())
+
+// recordAudit appends an entry to the audit log. Failures are logged but
+// never block the action being audited.
+func recordAudit(action, target string, details map[string]string) {
+	if _, err := auditLog.Append(audit.Entry{Action: action, Target: target, Details: details}); err != nil {
+		logEvent(eventlog.Error, eventlog.TypeAudit, "Failed to write audit log", eventlog.Fields{"action": action, "target": target, "error": err.Error()})
+	}
+}
+
+// tokenFingerprint identifies a token in the audit log without revealing
+// it, so entries for the same token can be matched up.
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// --- Audit Command ---
+func runAudit(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: nexa audit verify|show [--action name] [--json]")
+	}
+	switch args[0] {
+	case "verify":
+		return auditVerify()
+	case "show":
+		return auditShow(args[1:])
+	}
+	return fmt.Errorf("unknown audit command %q", args[0])
+}
+
+// auditVerify checks the hash chain and fails if any entry was edited or
+// removed.
+func auditVerify() error {
+	r, err := auditLog.Verify()
+	if err != nil {
+		return err
+	}
+	for _, p := range r.Problems {
+		fmt.Println(p)
+	}
+	if !r.OK() {
+		return fmt.Errorf("%s: chain verification failed (%d problem(s) in %d entries); the audit log was edited or truncated", auditLog.Path(), len(r.Problems), r.Entries)
+	}
+	fmt.Printf("%s: %d entries, chain intact\n", auditLog.Path(), r.Entries)
+	if r.Head != "" {
+		fmt.Println("Head:", r.Head)
+	}
+	return nil
+}
+
+// auditShow prints entries, optionally only those for one action or
+// action group such as "token".
+func auditShow(args []string) error {
+	fset := flag.NewFlagSet("audit show", flag.ContinueOnError)
+	action := fset.String("action", "", "only show this action, e.g. token.set, or group, e.g. token")
+	asJSON := fset.Bool("json", false, "print entries as JSON lines")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	entries, err := auditLog.Read()
+	if err != nil {
+		return err
+	}
+	var shown []audit.Entry
+	for _, e := range entries {
+		if e.Matches(*action) {
+			shown = append(shown, e)
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range shown {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(shown) == 0 {
+		fmt.Println("No audit entries")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEQ\tTIME\tACTOR\tACTION\tTARGET\tDETAILS")
+	for _, e := range shown {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Action, e.Target, formatDetails(e.Details))
+	}
+	return tw.Flush()
+}
+
+func formatDetails(details map[string]string) string {
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + details[k]
+	}
+	return strings.Join(parts, " ")
+}
+
+// tokenAuditDetails describes a token being set: its fingerprint and, when
+// the hub confirmed it, the account it belongs to.
+func tokenAuditDetails(token string, id hub.Identity, err error) map[string]string {
+	details := map[string]string{"token": tokenFingerprint(token)}
+	if err != nil {
+		details["verified"] = "false"
+		return details
+	}
+	details["account"] = id.Name
+	if scope := id.Scope(); scope != "" {
+		details["scope"] = scope
+	}
+	return details
+}
@@ -0,0 +1,266 @@
+// Package audit keeps a tamper-evident record of security-relevant actions
+// such as token changes, job submissions and pushes. Entries are appended
+// as JSON lines and each one carries the hash of its predecessor, so an
+// edited or removed entry breaks the chain. The audit log is separate from
+// the debug events in Tune.log and is never rotated.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/filelock"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+// Actions recorded in the audit log.
+const (
+	TokenSet       = "token.set"
+	TokenClear     = "token.clear"
+	JobSubmit      = "job.submit"
+	JobCancel      = "job.cancel"
+	ArtifactDelete = "artifact.delete"
+	Push           = "push"
+)
+
+// Entry is one audited action.
+type Entry struct {
+	Seq     int               `json:"seq"`
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor"`
+	Action  string            `json:"action"`
+	Target  string            `json:"target"`
+	Details map[string]string `json:"details,omitempty"`
+	// Prev is the hash of the preceding entry, empty for the first.
+	Prev string `json:"prev"`
+	// Hash covers every other field, including Prev.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Sum returns the hash of the entry with its Hash field ignored.
+func (e Entry) Sum() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether the entry's action is action or, for a prefix
+// such as "token", one of its sub-actions.
+func (e Entry) Matches(action string) bool {
+	return action == "" || e.Action == action || strings.HasPrefix(e.Action, action+".")
+}
+
+// Actor identifies the local user as user@host.
+func Actor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return name + "@" + host
+}
+
+// DefaultPath returns the audit log location in the XDG state directory.
+func DefaultPath() string {
+	return filepath.Join(xdg.StateHome(), "audit.log")
+}
+
+// Log is an append-only audit log file.
+type Log struct {
+	path string
+}
+
+// Open returns the audit log at path. The file is created on the first
+// append.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the file backing the log.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains e to the last entry and writes it. Seq, Prev and Hash are
+// filled in; Time and Actor default to now and the local user.
+func (l *Log) Append(e Entry) (Entry, error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return e, err
+	}
+	unlock, err := filelock.Lock(l.path)
+	if err != nil {
+		return e, err
+	}
+	defer unlock()
+
+	last, err := l.last()
+	if err != nil {
+		return e, err
+	}
+	e.Seq = last.Seq + 1
+	e.Prev = last.Hash
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	if e.Actor == "" {
+		e.Actor = Actor()
+	}
+	e.Hash = e.Sum()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return e, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return e, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return e, err
+	}
+	return e, f.Close()
+}
+
+// last returns the final entry, or the zero entry for an empty log.
+func (l *Log) last() (Entry, error) {
+	var e Entry
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return e, err
+	}
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return e, nil
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("%s: last entry is unreadable: %w", l.path, err)
+	}
+	return e, nil
+}
+
+// Read returns every entry in order. A missing file is an empty log.
+func (l *Log) Read() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return entries, fmt.Errorf("%s:%d: %w", l.path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// Problem is a break in the hash chain found by Verify.
+type Problem struct {
+	Line   int
+	Seq    int
+	Reason string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d (seq %d): %s", p.Line, p.Seq, p.Reason)
+}
+
+// Report summarizes a verification pass.
+type Report struct {
+	Entries  int
+	Head     string
+	Problems []Problem
+}
+
+// OK reports whether the chain is intact.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify walks the log and checks every entry's hash, its link to the
+// previous entry and the sequence numbers. Edited entries fail their hash;
+// removed entries leave a gap in the sequence and a broken link. Removing
+// entries from the end cannot be detected from the file alone, so the
+// report carries the head hash for comparison with an earlier run.
+func (l *Log) Verify() (Report, error) {
+	var r Report
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	defer f.Close()
+
+	var prev Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			r.Problems = append(r.Problems, Problem{Line: n, Seq: prev.Seq + 1, Reason: "unreadable entry: " + err.Error()})
+			continue
+		}
+		r.Entries++
+		problem := func(reason string) {
+			r.Problems = append(r.Problems, Problem{Line: n, Seq: e.Seq, Reason: reason})
+		}
+		if e.Hash != e.Sum() {
+			problem("entry was modified (hash mismatch)")
+		}
+		switch {
+		case e.Seq == prev.Seq+2:
+			problem(fmt.Sprintf("entry %d is missing", prev.Seq+1))
+		case e.Seq > prev.Seq+2:
+			problem(fmt.Sprintf("entries %d-%d are missing", prev.Seq+1, e.Seq-1))
+		case e.Seq <= prev.Seq:
+			problem(fmt.Sprintf("sequence goes back from %d", prev.Seq))
+		}
+		if e.Prev != prev.Hash {
+			problem("chain broken: previous hash does not match")
+		}
+		prev = e
+	}
+	r.Head = prev.Hash
+	return r, sc.Err()
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog appends n token.set entries to a fresh log and returns it.
+func writeLog(t *testing.T, n int) *Log {
+	t.Helper()
+	l := Open(filepath.Join(t.TempDir(), "audit.log"))
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(Entry{Action: TokenSet, Target: "HF_TOKEN", Actor: "test@host"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+func rewrite(t *testing.T, l *Log, fn func(lines []string) []string) {
+	t.Helper()
+	data, err := os.ReadFile(l.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	out := strings.Join(fn(lines), "\n") + "\n"
+	if err := os.WriteFile(l.Path(), []byte(out), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppendChains(t *testing.T) {
+	l := writeLog(t, 3)
+	entries, err := l.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != i+1 {
+			t.Errorf("entry %d: seq %d", i, e.Seq)
+		}
+		if i > 0 && e.Prev != entries[i-1].Hash {
+			t.Errorf("entry %d not chained to its predecessor", i)
+		}
+	}
+	r, err := l.Verify()
+	if err != nil || !r.OK() || r.Entries != 3 || r.Head != entries[2].Hash {
+		t.Fatalf("Verify = %+v, %v", r, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(lines []string) []string
+		want string
+	}{
+		{"edited", func(l []string) []string {
+			l[1] = strings.Replace(l[1], "HF_TOKEN", "other", 1)
+			return l
+		}, "modified"},
+		{"removed", func(l []string) []string {
+			return append(l[:1], l[2:]...)
+		}, "entry 2 is missing"},
+		{"removed first", func(l []string) []string {
+			return l[1:]
+		}, "entry 1 is missing"},
+		{"reordered", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, "chain broken"},
+		{"garbled", func(l []string) []string {
+			l[3] = "{not json"
+			return l
+		}, "unreadable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := writeLog(t, 4)
+			rewrite(t, l, tt.edit)
+			r, err := l.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.OK() {
+				t.Fatal("tampering not detected")
+			}
+			var reasons []string
+			for _, p := range r.Problems {
+				reasons = append(reasons, p.Reason)
+			}
+			if got := strings.Join(reasons, "; "); !strings.Contains(got, tt.want) {
+				t.Errorf("problems %q, want one mentioning %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	e := Entry{Action: TokenSet}
+	for action, want := range map[string]bool{"": true, "token": true, "token.set": true, "token.clear": false, "tok": false} {
+		if got := e.Matches(action); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", action, got, want)
+		}
+	}
+}
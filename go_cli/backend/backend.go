@@ -0,0 +1,180 @@
+// Package backend is the HTTP client for the local Python backends
+// (trainer_server.py and session_server.py). Endpoints are either TCP URLs
+// such as http://localhost:8770 or Unix domain sockets written as
+// unix:///path/to/socket; both are used the same way.
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// socketScheme prefixes Unix domain socket endpoints.
+const socketScheme = "unix://"
+
+// socketHost is the placeholder host used in request URLs for sockets.
+const socketHost = "http://localhost"
+
+// Client sends requests to one backend endpoint.
+type Client struct {
+	// Endpoint is the endpoint as configured.
+	Endpoint string
+	HTTP     *http.Client
+
+	base string
+}
+
+// IsSocket reports whether endpoint names a Unix domain socket.
+func IsSocket(endpoint string) bool {
+	return strings.HasPrefix(endpoint, socketScheme)
+}
+
+// New returns a client for endpoint with the given request timeout.
+func New(endpoint string, timeout time.Duration) (*Client, error) {
+	c := &Client{Endpoint: endpoint, HTTP: &http.Client{Timeout: timeout}}
+	if IsSocket(endpoint) {
+		path := strings.TrimPrefix(endpoint, socketScheme)
+		if path == "" {
+			return nil, fmt.Errorf("backend: %q has no socket path", endpoint)
+		}
+		c.base = socketHost
+		c.HTTP.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+		return c, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("backend: invalid endpoint %q, want http(s)://host:port or unix:///path", endpoint)
+	}
+	c.base = strings.TrimRight(endpoint, "/")
+	return c, nil
+}
+
+// NewOrInvalid is New for endpoints that have already been validated, e.g.
+// by the config loader. It never fails: an invalid endpoint yields a client
+// whose requests fail with the parse error.
+func NewOrInvalid(endpoint string, timeout time.Duration) *Client {
+	c, err := New(endpoint, timeout)
+	if err != nil {
+		return &Client{Endpoint: endpoint, HTTP: &http.Client{Transport: errTransport{err}}, base: socketHost}
+	}
+	return c
+}
+
+type errTransport struct{ err error }
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) { return nil, t.err }
+
+// WithTimeout returns a copy of c, sharing its transport, whose requests
+// time out after timeout instead.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	cp, hc := *c, *c.HTTP
+	hc.Timeout = timeout
+	cp.HTTP = &hc
+	return &cp
+}
+
+// URL returns the request URL for path on this endpoint.
+func (c *Client) URL(path string) string {
+	return c.base + path
+}
+
+// Get sends a GET request for path.
+func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.HTTP.Do(req)
+}
+
+// Post sends a POST request for path.
+func (c *Client) Post(ctx context.Context, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(path), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.HTTP.Do(req)
+}
+
+// Health is the outcome of one /health probe.
+type Health struct {
+	Endpoint   string            `json:"endpoint"`
+	Status     string            `json:"status"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Latency    time.Duration     `json:"latency"`
+	Components map[string]string `json:"components,omitempty"`
+	Body       []byte            `json:"-"`
+	Err        error             `json:"-"`
+}
+
+// OK reports whether the backend answered and called itself healthy.
+func (h Health) OK() bool {
+	return h.Err == nil && h.Status == "ok"
+}
+
+// CheckHealth probes GET /health. Transport failures and bad status codes
+// are reported in Health.Err with Status "unavailable" or "error".
+func (c *Client) CheckHealth(ctx context.Context) Health {
+	h := Health{Endpoint: c.Endpoint}
+	start := time.Now()
+	resp, err := c.Get(ctx, "/health")
+	if err != nil {
+		h.Status, h.Err, h.Latency = "unavailable", err, time.Since(start)
+		return h
+	}
+	defer resp.Body.Close()
+	h.StatusCode = resp.StatusCode
+	h.Body, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	h.Latency = time.Since(start)
+	switch {
+	case err != nil:
+		h.Status, h.Err = "error", err
+		return h
+	case resp.StatusCode != http.StatusOK:
+		h.Status, h.Err = "error", fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, c.Endpoint)
+		return h
+	}
+	var body struct {
+		Status     string            `json:"status"`
+		Components map[string]string `json:"components"`
+	}
+	if err := json.Unmarshal(h.Body, &body); err != nil {
+		h.Status, h.Err = "error", fmt.Errorf("decoding health response from %s: %w", c.Endpoint, err)
+		return h
+	}
+	h.Status, h.Components = body.Status, body.Components
+	return h
+}
+
+// Failover probes endpoints in order and returns a client for the first
+// healthy one, together with every probe made. If none is healthy the
+// client is nil.
+func Failover(ctx context.Context, endpoints []string, timeout time.Duration) (*Client, []Health) {
+	var probes []Health
+	for _, e := range endpoints {
+		c, err := New(e, timeout)
+		if err != nil {
+			probes = append(probes, Health{Endpoint: e, Status: "invalid", Err: err})
+			continue
+		}
+		h := c.CheckHealth(ctx)
+		probes = append(probes, h)
+		if h.OK() {
+			return c, probes
+		}
+	}
+	return nil, probes
+}
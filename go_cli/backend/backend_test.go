@@ -0,0 +1,150 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveSocket runs handler on a Unix socket in a temporary directory and
+// returns its unix:// endpoint.
+func serveSocket(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backend.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := &http.Server{Handler: handler}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return "unix://" + path
+}
+
+func healthHandler(status string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":"`+status+`","components":{"trainer":"`+status+`"}}`)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	return mux
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+		wantURL  string
+	}{
+		{"http://localhost:8770", false, "http://localhost:8770/health"},
+		{"http://localhost:8770/", false, "http://localhost:8770/health"},
+		{"https://trainer.internal", false, "https://trainer.internal/health"},
+		{"unix:///run/nexa/trainer.sock", false, "http://localhost/health"},
+		{"unix://", true, ""},
+		{"localhost:8770", true, ""},
+		{"ftp://localhost", true, ""},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.endpoint, time.Second)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+			continue
+		}
+		if err == nil && c.URL("/health") != tt.wantURL {
+			t.Errorf("New(%q).URL = %q, want %q", tt.endpoint, c.URL("/health"), tt.wantURL)
+		}
+	}
+}
+
+func TestSocketRequests(t *testing.T) {
+	endpoint := serveSocket(t, healthHandler("ok"))
+	c, err := New(endpoint, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := c.CheckHealth(context.Background())
+	if !h.OK() || h.Components["trainer"] != "ok" || h.Endpoint != endpoint {
+		t.Fatalf("CheckHealth = %+v", h)
+	}
+	resp, err := c.Post(context.Background(), "/echo", "text/plain", strings.NewReader("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ping" {
+		t.Errorf("echo = %q", body)
+	}
+}
+
+func TestFailoverTreatsSocketsAndTCPAlike(t *testing.T) {
+	socketUp := serveSocket(t, healthHandler("ok"))
+	socketDegraded := serveSocket(t, healthHandler("degraded"))
+	tcp := httptest.NewServer(healthHandler("ok"))
+	defer tcp.Close()
+	deadSocket := "unix://" + filepath.Join(t.TempDir(), "missing.sock")
+
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      string
+		probes    int
+	}{
+		{"socket first", []string{socketUp, tcp.URL}, socketUp, 1},
+		{"dead socket falls back to tcp", []string{deadSocket, tcp.URL}, tcp.URL, 2},
+		{"degraded socket falls back to socket", []string{socketDegraded, socketUp}, socketUp, 2},
+		{"invalid endpoint skipped", []string{"bogus", socketUp}, socketUp, 2},
+		{"none healthy", []string{deadSocket, socketDegraded}, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, probes := Failover(context.Background(), tt.endpoints, time.Second)
+			got := ""
+			if c != nil {
+				got = c.Endpoint
+			}
+			if got != tt.want {
+				t.Errorf("chose %q, want %q", got, tt.want)
+			}
+			if len(probes) != tt.probes {
+				t.Errorf("%d probes, want %d", len(probes), tt.probes)
+			}
+			for _, h := range probes[:len(probes)-1] {
+				if h.OK() {
+					t.Errorf("probe of %s is healthy but failover moved on", h.Endpoint)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOrInvalid(t *testing.T) {
+	c := NewOrInvalid("bogus", time.Second)
+	if _, err := c.Get(context.Background(), "/health"); err == nil || !strings.Contains(err.Error(), "invalid endpoint") {
+		t.Errorf("request through invalid endpoint: %v, want the parse error", err)
+	}
+	if c.Endpoint != "bogus" {
+		t.Errorf("Endpoint = %q", c.Endpoint)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	c, err := New("unix:///tmp/trainer.sock", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := c.WithTimeout(time.Minute)
+	if long.HTTP.Timeout != time.Minute || c.HTTP.Timeout != time.Second {
+		t.Errorf("timeouts = %s and %s, want 1m0s for the copy only", long.HTTP.Timeout, c.HTTP.Timeout)
+	}
+	if long.HTTP.Transport != c.HTTP.Transport || long.URL("/train") != c.URL("/train") {
+		t.Error("copy does not reach the same endpoint")
+	}
+}
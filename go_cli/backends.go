@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/session"
+)
+
+const (
+	// trainerTimeout bounds trainer API calls other than job submission.
+	trainerTimeout = 5 * time.Second
+	// submitTimeout bounds job submission, which a busy trainer may be slow
+	// to accept.
+	submitTimeout = time.Minute
+	// probeTimeout bounds a single /health probe during failover.
+	probeTimeout = 2 * time.Second
+)
+
+// --- Backends ---
+// The trainer endpoint chosen by the last health check is reused until a
+// request to it fails; then the next call probes the endpoints again.
+var (
+	trainerMu     sync.Mutex
+	activeTrainer *backend.Client
+)
+
+// probeEndpoints runs failover over endpoints, logging every probe the
+// same way for TCP and socket endpoints.
+func probeEndpoints(name string, endpoints []string) (*backend.Client, []backend.Health) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(endpoints))*probeTimeout)
+	defer cancel()
+	c, probes := backend.Failover(ctx, endpoints, probeTimeout)
+	for _, h := range probes {
+		logHealthProbe(name, h)
+	}
+	return c, probes
+}
+
+func logHealthProbe(name string, h backend.Health) {
+	transport := "tcp"
+	if backend.IsSocket(h.Endpoint) {
+		transport = "unix"
+	}
+	fields := eventlog.Fields{
+		"backend":    name,
+		"endpoint":   h.Endpoint,
+		"transport":  transport,
+		"status":     h.Status,
+		"latency_ms": float64(h.Latency.Microseconds()) / 1000,
+	}
+	if h.StatusCode != 0 {
+		fields["status_code"] = h.StatusCode
+	}
+	if h.Components != nil {
+		fields["components"] = h.Components
+	}
+	level := eventlog.Info
+	if !h.OK() {
+		level = eventlog.Warn
+	}
+	if h.Err != nil {
+		fields["error"] = h.Err.Error()
+	}
+	logEvent(level, eventlog.TypeHealthCheck, "Backend health checked", fields)
+}
+
+// selectTrainer probes the trainer endpoints and remembers the first
+// healthy one.
+func selectTrainer() (*backend.Client, []backend.Health) {
+	c, probes := probeEndpoints("trainer", appConfig.TrainerEndpoints())
+	if c != nil {
+		c.HTTP.Timeout = trainerTimeout
+	}
+	trainerMu.Lock()
+	activeTrainer = c
+	trainerMu.Unlock()
+	return c, probes
+}
+
+// trainerClient returns the trainer chosen by the last health check,
+// probing again if there is none. Without any healthy endpoint it falls
+// back to the primary so that callers see its error.
+func trainerClient() *backend.Client {
+	trainerMu.Lock()
+	c := activeTrainer
+	trainerMu.Unlock()
+	if c != nil {
+		return c
+	}
+	if c, _ := selectTrainer(); c != nil {
+		return c
+	}
+	return backend.NewOrInvalid(appConfig.TrainerURL, trainerTimeout)
+}
+
+// trainerFailed forgets the chosen trainer after a transport error.
+func trainerFailed(c *backend.Client) {
+	trainerMu.Lock()
+	if activeTrainer == c {
+		activeTrainer = nil
+	}
+	trainerMu.Unlock()
+}
+
+// sessionClient returns a client for the first healthy session server
+// endpoint, or for the primary one if none answers.
+func sessionClient() *session.Client {
+	endpoints := appConfig.SessionEndpoints()
+	if len(endpoints) == 1 {
+		return session.NewClient(endpoints[0])
+	}
+	if c, _ := probeEndpoints("session", endpoints); c != nil {
+		c.HTTP.Timeout = session.Timeout
+		return session.New(c)
+	}
+	return session.NewClient(appConfig.SessionURL)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// --- Subcommands ---
+// Running the binary without arguments starts the TUI; otherwise the first
+// argument selects one of the headless commands below.
+
+type command struct {
+	usage string
+	short string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"audit": {
+		usage: "audit verify|show [--action name] [--json]",
+		short: "Verify or list the tamper-evident audit log",
+		run:   runAudit,
+	},
+	"card": {
+		usage: "card [--print] <job>",
+		short: "Write a Hugging Face model card (README.md) for a past job",
+		run:   runCard,
+	},
+	"diff": {
+		usage: "diff [--json] <runA> <runB>",
+		short: "Compare the adapter config and weights of two runs",
+		run:   runDiff,
+	},
+	"push": {
+		usage: "push --repo org/name [--private] <run>",
+		short: "Publish a finished adapter to the Hugging Face Hub",
+		run:   runPush,
+	},
+	"report": {
+		usage: "report [--format md|html|json] [-o file] <job>",
+		short: "Export a report of a past job from the job history",
+		run:   runReport,
+	},
+	"vault": {
+		usage: "vault init|status|lock|wipe [--yes]",
+		short: "Manage the encrypted token vault",
+		run:   runVault,
+	},
+	"monitor": {
+		usage: "monitor [--log path] [--listen addr] [--interval 5s] [--trainer urls] [--session urls] [--output dir] [--history path]",
+		short: "Probe the backends and serve Prometheus metrics and uptime status",
+		run:   runMonitor,
+	},
+	"inspect": {
+		usage: "inspect [--json] <path>...",
+		short: "Show tensor names, dtypes, shapes and sizes of .safetensors files",
+		run:   runInspect,
+	},
+}
+
+func runCommand(name string, args []string) int {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return 0
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "nexa: unknown command %q\n\n", name)
+		printUsage()
+		return 2
+	}
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "nexa %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "Usage: nexa [command] [args]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the interactive TUI is started.\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-36s %s\n", commands[name].usage, commands[name].short)
+	}
+}
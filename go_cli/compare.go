@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/adapter"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+const (
+	chartWidth  = 60
+	chartHeight = 12
+)
+
+var (
+	changedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFD21F")).
+			Bold(true)
+	seriesColors = []lipgloss.Color{"#39FF14", "#00BFFF", "#FF69B4", "#FFA500", "#A020F0", "#00FFFF"}
+	seriesMarks  = []rune{'●', '■', '▲', '◆', '✚', '✱'}
+)
+
+// runSummary is everything known about a past job, combining the history
+// record with the trainer state and adapter config found on disk.
+type runSummary struct {
+	job     history.Job
+	state   *trainerstate.State
+	adapter map[string]any
+}
+
+func loadRunSummary(job history.Job) runSummary {
+	dir := filepath.Join(outputRoot, job.Output)
+	s := runSummary{job: job}
+	s.state, _ = trainerstate.Find(dir)
+	if r, err := adapter.Load(dir); err == nil {
+		s.adapter = r.Config
+	}
+	return s
+}
+
+func (s runSummary) finalLoss() string {
+	if s.state == nil {
+		return "-"
+	}
+	if v, ok := s.state.FinalLoss(); ok {
+		return fmt.Sprintf("%.4f", v)
+	}
+	return "-"
+}
+
+func (s runSummary) bestLoss() string {
+	if s.state == nil {
+		return "-"
+	}
+	if v, ok := s.state.BestLoss(); ok {
+		return fmt.Sprintf("%.4f", v)
+	}
+	return "-"
+}
+
+func (s runSummary) stateField(get func(*trainerstate.State) int) string {
+	if s.state == nil {
+		return "-"
+	}
+	return fmt.Sprint(get(s.state))
+}
+
+func (s runSummary) adapterField(key string) string {
+	if v, ok := s.adapter[key]; ok {
+		return formatConfigValue(v)
+	}
+	return "-"
+}
+
+// compareRows lists the parameter table rows shown in the comparison view.
+var compareRows = []struct {
+	label string
+	value func(runSummary) string
+}{
+	{"Status", func(s runSummary) string { return s.job.Status }},
+	{"Base model", func(s runSummary) string { return s.job.Model }},
+	{"Dataset", func(s runSummary) string { return s.job.Dataset }},
+	{"Token profile", func(s runSummary) string { return s.job.Profile }},
+	{"Output", func(s runSummary) string { return s.job.Output }},
+	{"Local", func(s runSummary) string { return fmt.Sprint(s.job.Local) }},
+	{"Submitted", func(s runSummary) string { return s.job.SubmittedAt.Format("2006-01-02 15:04") }},
+	{"Duration", func(s runSummary) string { return s.job.Duration().Round(time.Second).String() }},
+	{"Final loss", runSummary.finalLoss},
+	{"Best loss", runSummary.bestLoss},
+	{"Steps", func(s runSummary) string {
+		return s.stateField(func(t *trainerstate.State) int { return t.GlobalStep })
+	}},
+	{"Epochs", func(s runSummary) string {
+		return s.stateField(func(t *trainerstate.State) int { return t.NumTrainEpochs })
+	}},
+	{"Batch size", func(s runSummary) string {
+		return s.stateField(func(t *trainerstate.State) int { return t.TrainBatchSize })
+	}},
+	{"LoRA rank", func(s runSummary) string { return s.adapterField("r") }},
+	{"LoRA alpha", func(s runSummary) string { return s.adapterField("lora_alpha") }},
+	{"Target modules", func(s runSummary) string { return s.adapterField("target_modules") }},
+}
+
+// compareRuns renders the side-by-side comparison of the given jobs. Rows
+// whose values differ between runs are highlighted.
+func compareRuns(jobs []history.Job) []string {
+	runs := make([]runSummary, len(jobs))
+	for i, j := range jobs {
+		runs[i] = loadRunSummary(j)
+	}
+
+	header := []string{"Job"}
+	for i, r := range runs {
+		header = append(header, lipgloss.NewStyle().Foreground(seriesColors[i%len(seriesColors)]).Render(shortID(r.job.ID)))
+	}
+	table := [][]string{header}
+	changed := []bool{false}
+	for _, row := range compareRows {
+		cells := []string{row.label}
+		differs := false
+		for _, r := range runs {
+			v := row.value(r)
+			if len(cells) > 1 && v != cells[1] {
+				differs = true
+			}
+			cells = append(cells, v)
+		}
+		table = append(table, cells)
+		changed = append(changed, differs)
+	}
+
+	widths := make([]int, len(header))
+	for _, cells := range table {
+		for i, c := range cells {
+			widths[i] = max(widths[i], lipgloss.Width(c))
+		}
+	}
+	var lines []string
+	for r, cells := range table {
+		var b strings.Builder
+		for i, c := range cells {
+			b.WriteString(c + strings.Repeat(" ", widths[i]-lipgloss.Width(c)+2))
+		}
+		line := strings.TrimRight(b.String(), " ")
+		if changed[r] {
+			line = changedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "Loss curves:")
+	var series [][]trainerstate.Point
+	for _, r := range runs {
+		var pts []trainerstate.Point
+		if r.state != nil {
+			pts = r.state.Losses()
+		}
+		series = append(series, pts)
+	}
+	lines = append(lines, renderLossChart(series, chartWidth, chartHeight)...)
+	for i, r := range runs {
+		style := lipgloss.NewStyle().Foreground(seriesColors[i%len(seriesColors)])
+		lines = append(lines, style.Render(fmt.Sprintf("  %c %s (%s)", seriesMarks[i%len(seriesMarks)], shortID(r.job.ID), r.job.Output)))
+	}
+	return lines
+}
+
+// renderLossChart overlays loss curves on a shared step/loss grid.
+func renderLossChart(series [][]trainerstate.Point, width, height int) []string {
+	maxStep, lo, hi := 0, math.Inf(1), math.Inf(-1)
+	for _, pts := range series {
+		for _, p := range pts {
+			maxStep = max(maxStep, p.Step)
+			lo, hi = math.Min(lo, p.Loss), math.Max(hi, p.Loss)
+		}
+	}
+	if maxStep == 0 || math.IsInf(lo, 0) {
+		return []string{"  (no loss data in trainer_state.json)"}
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	grid := make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+		for x := range grid[y] {
+			grid[y][x] = -1
+		}
+	}
+	col := func(step int) int { return min(width-1, step*(width-1)/maxStep) }
+	row := func(loss float64) int { return height - 1 - int(math.Round((loss-lo)/(hi-lo)*float64(height-1))) }
+	for i, pts := range series {
+		for k, p := range pts {
+			grid[row(p.Loss)][col(p.Step)] = i
+			if k == 0 {
+				continue
+			}
+			// Interpolate between consecutive points so sparse logs still
+			// draw a continuous curve.
+			prev := pts[k-1]
+			x0, x1 := col(prev.Step), col(p.Step)
+			for x := x0 + 1; x < x1; x++ {
+				t := float64(x-x0) / float64(x1-x0)
+				grid[row(prev.Loss+t*(p.Loss-prev.Loss))][x] = i
+			}
+		}
+	}
+
+	lines := make([]string, 0, height+2)
+	for y, cells := range grid {
+		label := "        "
+		switch y {
+		case 0:
+			label = fmt.Sprintf("%8.4f", hi)
+		case height - 1:
+			label = fmt.Sprintf("%8.4f", lo)
+		}
+		var b strings.Builder
+		for _, s := range cells {
+			if s < 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			style := lipgloss.NewStyle().Foreground(seriesColors[s%len(seriesColors)])
+			b.WriteString(style.Render(string(seriesMarks[s%len(seriesMarks)])))
+		}
+		lines = append(lines, label+" │"+b.String())
+	}
+	lines = append(lines, "         └"+strings.Repeat("─", width))
+	lines = append(lines, fmt.Sprintf("          0%*s", width-1, fmt.Sprintf("step %d", maxStep)))
+	return lines
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// --- Job History View ---
+func (m model) historyView() string {
+	out := headerStyle.Render("Job History") + "\n\n"
+	if len(m.historyJobs) == 0 {
+		out += "No jobs submitted yet.\n"
+	}
+	for i, j := range m.historyJobs {
+		mark := "[ ] "
+		if m.historyMarks[j.ID] {
+			mark = "[x] "
+		}
+		line := fmt.Sprintf("%s%s  %-9s %-12s %-12s %s", mark, shortID(j.ID), j.Status, j.Model, j.Dataset, j.Output)
+		if i == m.menuIdx {
+			out += selectedStyle.Render("> "+line) + "\n"
+		} else {
+			out += "  " + line + "\n"
+		}
+	}
+	if m.historyStatus != "" {
+		out += "\n" + m.historyStatus + "\n"
+	}
+	return out + "\n[Space] Mark  [c] Compare marked  [e] Export report  [m] Model card  [p] Publish  [r] Refresh  [ESC] Back"
+}
+
+func (m model) markedJobs() []history.Job {
+	var marked []history.Job
+	for _, j := range m.historyJobs {
+		if m.historyMarks[j.ID] {
+			marked = append(marked, j)
+		}
+	}
+	return marked
+}
+
+func loadHistory() []history.Job {
+	jobs, err := jobHistory.List()
+	if err != nil {
+		logEvent(eventlog.Error, eventlog.TypeHistory, "Failed to read job history", eventlog.Fields{"error": err.Error()})
+	}
+	return jobs
+}
@@ -0,0 +1,120 @@
+// Package config loads the user's Nexa settings from config.json in the XDG
+// config directory. Every setting has a default so the file is optional.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/monitor"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/notify"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+// Config holds the endpoints and options shared by the TUI and the
+// headless commands.
+type Config struct {
+	// TrainerURL is the endpoint of trainer_server.py: a base URL or a
+	// unix:///path/to/socket.
+	TrainerURL string `json:"trainer_url"`
+	// TrainerFallbacks are tried in order when TrainerURL is unhealthy.
+	TrainerFallbacks []string `json:"trainer_fallbacks,omitempty"`
+	// SessionURL is the endpoint of session_server.py, which holds the
+	// Hugging Face token. A Unix socket keeps the token off the network.
+	SessionURL string `json:"session_url"`
+	// SessionFallbacks are tried in order when SessionURL is unhealthy.
+	SessionFallbacks []string `json:"session_fallbacks,omitempty"`
+	// HubURL is the base URL of the Hugging Face Hub or a compatible
+	// server used for publishing adapters.
+	HubURL string `json:"hub_url"`
+	// Notify configures how finished jobs are announced.
+	Notify notify.Config `json:"notify"`
+	// Log configures where Tune.log lives and how it is rotated.
+	Log LogConfig `json:"log"`
+	// Monitor configures the `nexa monitor` metrics daemon.
+	Monitor monitor.Config `json:"monitor"`
+	// Secrets lists extra values, such as webhook keys, that are masked
+	// wherever text is logged, displayed or exported.
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// LogConfig locates the event log and sets its rotation policy.
+type LogConfig struct {
+	Path string `json:"path"`
+	eventlog.RotateConfig
+}
+
+// Default returns the settings used when no config file exists.
+func Default() Config {
+	return Config{
+		TrainerURL: "http://localhost:8770",
+		SessionURL: "http://localhost:8765",
+		HubURL:     "https://huggingface.co",
+		Log: LogConfig{
+			Path: filepath.Join(xdg.StateHome(), "Tune.log"),
+			RotateConfig: eventlog.RotateConfig{
+				MaxSizeMB:  10,
+				MaxAgeDays: 30,
+				Compress:   true,
+				Retain:     5,
+			},
+		},
+		Monitor: monitor.DefaultConfig(),
+	}
+}
+
+// Path returns the location of the config file.
+func Path() string {
+	if p := os.Getenv("NEXA_CONFIG"); p != "" {
+		return p
+	}
+	return filepath.Join(xdg.ConfigHome(), "config.json")
+}
+
+// Load reads the config file over the defaults and applies environment
+// overrides. A missing file is not an error.
+func Load() (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(Path())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Default(), fmt.Errorf("%s: %w", Path(), err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return Default(), err
+	}
+	if v := os.Getenv("NEXA_HUB_URL"); v != "" {
+		cfg.HubURL = v
+	}
+	if v := os.Getenv("NEXA_TRAINER_URL"); v != "" {
+		cfg.TrainerURL = v
+	}
+	if v := os.Getenv("NEXA_SESSION_URL"); v != "" {
+		cfg.SessionURL = v
+	}
+	if v := os.Getenv("NEXA_LOG"); v != "" {
+		cfg.Log.Path = v
+	}
+	for _, e := range append(cfg.TrainerEndpoints(), cfg.SessionEndpoints()...) {
+		if _, err := backend.New(e, 0); err != nil {
+			return Default(), fmt.Errorf("%s: %w", Path(), err)
+		}
+	}
+	return cfg, nil
+}
+
+// TrainerEndpoints returns the trainer endpoint followed by its fallbacks.
+func (c Config) TrainerEndpoints() []string {
+	return append([]string{c.TrainerURL}, c.TrainerFallbacks...)
+}
+
+// SessionEndpoints returns the session endpoint followed by its fallbacks.
+func (c Config) SessionEndpoints() []string {
+	return append([]string{c.SessionURL}, c.SessionFallbacks...)
+}
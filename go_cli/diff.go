@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/adapter"
+)
+
+// --- Diff Command ---
+func runDiff(args []string) error {
+	fset := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fset.Bool("json", false, "print the diff as JSON")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 2 {
+		return errors.New("usage: nexa diff [--json] <runA> <runB>")
+	}
+	d, err := diffRuns(fset.Arg(0), fset.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	fmt.Print(formatDiff(d))
+	return nil
+}
+
+func diffRuns(runA, runB string) (*adapter.Diff, error) {
+	a, err := adapter.Load(resolveRunDir(runA))
+	if err != nil {
+		return nil, err
+	}
+	b, err := adapter.Load(resolveRunDir(runB))
+	if err != nil {
+		return nil, err
+	}
+	return adapter.Compare(a, b)
+}
+
+// resolveRunDir accepts either a path to a run directory or the bare output
+// name of a run under nexa_output/.
+func resolveRunDir(run string) string {
+	if fi, err := os.Stat(run); err == nil && fi.IsDir() {
+		return run
+	}
+	return filepath.Join(outputRoot, run)
+}
+
+// formatDiff renders an adapter diff as plain text for the diff command and
+// the TUI diff view.
+func formatDiff(d *adapter.Diff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A: %s\nB: %s\n\n", d.RunA, d.RunB)
+
+	b.WriteString("Config changes:\n")
+	if len(d.Config) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, c := range d.Config {
+		fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Key, formatConfigValue(c.A), formatConfigValue(c.B))
+	}
+
+	fmt.Fprintf(&b, "\nAdded tensors (%d):\n", len(d.Added))
+	for _, name := range d.Added {
+		fmt.Fprintf(&b, "  + %s\n", name)
+	}
+	fmt.Fprintf(&b, "\nRemoved tensors (%d):\n", len(d.Removed))
+	for _, name := range d.Removed {
+		fmt.Fprintf(&b, "  - %s\n", name)
+	}
+	fmt.Fprintf(&b, "\nReshaped tensors (%d):\n", len(d.Reshaped))
+	for _, c := range d.Reshaped {
+		fmt.Fprintf(&b, "  ~ %s: %s %s -> %s %s\n", c.Name, c.DTypeA, formatShape(c.ShapeA), c.DTypeB, formatShape(c.ShapeB))
+	}
+
+	fmt.Fprintf(&b, "\nMatched tensors (%d):\n", len(d.Matched))
+	if len(d.Matched) > 0 {
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  NAME\tSHAPE\t‖A‖\t‖B‖\t‖B-A‖\tREL\tMAX|Δ|\tCOS")
+		for _, s := range d.Matched {
+			fmt.Fprintf(tw, "  %s\t%s\t%.4g\t%.4g\t%.4g\t%.2f%%\t%.4g\t%.4f\n",
+				s.Name, formatShape(s.Shape), s.NormA, s.NormB, s.DeltaNorm, s.RelativeDelta*100, s.MaxAbsDelta, s.Cosine)
+		}
+		tw.Flush()
+	}
+	return b.String()
+}
+
+func formatConfigValue(v any) string {
+	if v == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,172 @@
+// Package eventlog writes and reads the structured JSON-lines events that
+// make up Tune.log. Each line is one Event; lines written by older versions
+// of the TUI are still understood as legacy free-text events.
+package eventlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+)
+
+// Level is the severity of an event.
+type Level string
+
+const (
+	Debug Level = "debug"
+	Info  Level = "info"
+	Warn  Level = "warn"
+	Error Level = "error"
+)
+
+// Event types written by the TUI and the headless commands.
+const (
+	TypeSessionStart = "session.start"
+	TypeSessionExit  = "session.exit"
+	TypeHealthCheck  = "health.check"
+	TypeJobSubmitted = "job.submitted"
+	TypeJobStatus    = "job.status"
+	TypeJobError     = "job.error"
+	TypeToken        = "token"
+	TypeSelection    = "ui.selection"
+	TypeMode         = "ui.mode"
+	TypeArtifact     = "artifact"
+	TypePublish      = "publish"
+	TypeNotify       = "notify"
+	TypeLogs         = "logs"
+	TypeHistory      = "history"
+	TypeAudit        = "audit"
+	// TypeLegacy marks free-text lines from before structured logging.
+	TypeLegacy = "legacy"
+)
+
+// timeLayout is how timestamps are shown to humans, matching the format of
+// the legacy free-text log.
+const timeLayout = "2006-01-02 15:04:05"
+
+// Fields carries event-specific data such as endpoint, job_id or latency.
+type Fields map[string]any
+
+// Event is a single line of the log.
+type Event struct {
+	Time    time.Time `json:"ts"`
+	Level   Level     `json:"level"`
+	Type    string    `json:"event"`
+	Session string    `json:"session,omitempty"`
+	Message string    `json:"msg,omitempty"`
+	Fields  Fields    `json:"fields,omitempty"`
+}
+
+// String renders the event as a human-readable log line.
+func (e Event) String() string {
+	if e.Type == TypeLegacy && e.Time.IsZero() {
+		return e.Message
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %-5s %s", e.Time.Local().Format(timeLayout), strings.ToUpper(string(e.Level)), e.Message)
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, e.Fields[k])
+	}
+	return b.String()
+}
+
+var legacyLine = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\] (.*)$`)
+
+// Parse decodes one log line. Lines that are not JSON are returned as
+// legacy events, with their timestamp when they carry one.
+func Parse(line string) Event {
+	if strings.HasPrefix(line, "{") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err == nil && e.Type != "" {
+			return e
+		}
+	}
+	e := Event{Level: Info, Type: TypeLegacy, Message: line}
+	if m := legacyLine.FindStringSubmatch(line); m != nil {
+		if ts, err := time.ParseInLocation(timeLayout, m[1], time.Local); err == nil {
+			e.Time, e.Message = ts, m[2]
+		}
+	}
+	return e
+}
+
+// NewSessionID returns a random identifier for one run of the TUI or of a
+// headless command.
+func NewSessionID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Logger appends events for one session to a log file, rotating it
+// according to Rotation before each write.
+type Logger struct {
+	path     string
+	session  string
+	Rotation RotateConfig
+
+	once   sync.Once
+	writer *Writer
+}
+
+// New returns a logger writing to path and tagging events with session.
+func New(path, session string) *Logger {
+	return &Logger{path: path, session: session}
+}
+
+// Path returns the log file the logger writes to.
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Session returns the session ID attached to every event.
+func (l *Logger) Session() string {
+	return l.session
+}
+
+// Log queues one event for the writer goroutine and returns it. Secrets in
+// the message and fields are masked before anything is written. The writer
+// is started on first use, so Rotation must be set before logging.
+func (l *Logger) Log(level Level, typ, msg string, fields Fields) Event {
+	msg = redact.String(msg)
+	if fields != nil {
+		fields = Fields(redact.Value(map[string]any(fields)).(map[string]any))
+	}
+	e := Event{Time: time.Now(), Level: level, Type: typ, Session: l.session, Message: msg, Fields: fields}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e
+	}
+	l.out().Write(line)
+	return e
+}
+
+func (l *Logger) out() *Writer {
+	l.once.Do(func() { l.writer = NewWriter(l.path, l.Rotation) })
+	return l.writer
+}
+
+// Flush blocks until every event logged so far is on disk.
+func (l *Logger) Flush() error {
+	return l.out().Flush()
+}
+
+// Close flushes pending events and stops the writer. Call it before exit.
+func (l *Logger) Close() error {
+	return l.out().Close()
+}
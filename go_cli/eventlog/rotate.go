@@ -0,0 +1,161 @@
+package eventlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/filelock"
+)
+
+// archiveTimeLayout names rotated files, e.g. Tune.log.20250630-142513.
+const archiveTimeLayout = "20060102-150405"
+
+// RotateConfig controls when the log is rotated and how many archives are
+// kept. Zero values disable the corresponding limit.
+type RotateConfig struct {
+	MaxSizeMB  int  `json:"max_size_mb"`
+	MaxAgeDays int  `json:"max_age_days"`
+	Compress   bool `json:"compress"`
+	Retain     int  `json:"retain"`
+}
+
+// NeedsRotation reports whether the log at path has outgrown cfg. The age
+// of a log is taken from its first event.
+func NeedsRotation(path string, cfg RotateConfig) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Size() == 0 {
+		return false
+	}
+	if cfg.MaxSizeMB > 0 && fi.Size() >= int64(cfg.MaxSizeMB)<<20 {
+		return true
+	}
+	if cfg.MaxAgeDays > 0 {
+		started := firstEventTime(path)
+		if started.IsZero() {
+			started = fi.ModTime()
+		}
+		return time.Since(started) >= time.Duration(cfg.MaxAgeDays)*24*time.Hour
+	}
+	return false
+}
+
+// Rotate moves the log at path aside as a timestamped archive, compressing
+// it if configured, and prunes archives beyond the retention count. It
+// returns the archive's path, or "" when there was no log to rotate. It
+// holds the same advisory lock as Writer, so it is safe to call while other
+// processes are logging.
+func Rotate(path string, cfg RotateConfig) (string, error) {
+	unlock, err := filelock.Lock(path)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return rotate(path, cfg)
+}
+
+func rotate(path string, cfg RotateConfig) (string, error) {
+	if !fileExists(path) {
+		return "", nil
+	}
+	stamp := time.Now().Format(archiveTimeLayout)
+	archive := path + "." + stamp
+	for i := 1; fileExists(archive) || fileExists(archive+".gz"); i++ {
+		archive = fmt.Sprintf("%s.%s-%d", path, stamp, i)
+	}
+	if err := os.Rename(path, archive); err != nil {
+		return "", err
+	}
+	if cfg.Compress {
+		if err := gzipFile(archive); err != nil {
+			return archive, err
+		}
+		archive += ".gz"
+	}
+	return archive, prune(path, cfg.Retain)
+}
+
+// Archives lists the rotated files of the log at path, newest first.
+func Archives(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	var archives []string
+	mtimes := map[string]time.Time{}
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".tmp") || strings.HasSuffix(m, ".lock") {
+			continue
+		}
+		if fi, err := os.Stat(m); err == nil {
+			archives = append(archives, m)
+			mtimes[m] = fi.ModTime()
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return mtimes[archives[i]].After(mtimes[archives[j]])
+	})
+	return archives
+}
+
+func prune(path string, retain int) error {
+	if retain <= 0 {
+		return nil
+	}
+	var errs []error
+	archives := Archives(path)
+	for i := retain; i < len(archives); i++ {
+		errs = append(errs, os.Remove(archives[i]))
+	}
+	return errors.Join(errs...)
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func firstEventTime(path string) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		if e := Parse(sc.Text()); !e.Time.IsZero() {
+			return e.Time
+		}
+	}
+	return time.Time{}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,168 @@
+package eventlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/filelock"
+)
+
+const (
+	// FlushInterval is how often buffered lines are written out.
+	FlushInterval = time.Second
+	// queueSize bounds the number of lines waiting for the writer goroutine.
+	queueSize = 1024
+	// maxBuffered forces a flush once this many bytes are pending.
+	maxBuffered = 64 << 10
+)
+
+// Writer appends lines to a file from a single goroutine. Callers enqueue
+// lines on a buffered channel; the goroutine batches them and writes on a
+// timer, when the buffer fills, or on Flush. Every write happens under an
+// advisory lock on path+".lock" so that several processes (the TUI, the
+// monitor, headless commands) can share one file, and rotation is checked
+// under the same lock. Errors from batches written in the background are
+// kept and returned by the next Flush or Close.
+type Writer struct {
+	path     string
+	rotation RotateConfig
+	lines    chan []byte
+	flushes  chan chan error
+	// done is closed by Close; run then writes what is left, reports the
+	// result on closed and exits.
+	done     chan struct{}
+	closed   chan error
+	close    sync.Once
+	closeErr error
+}
+
+// NewWriter starts a writer goroutine for path. Rotation is applied before
+// each batch is written.
+func NewWriter(path string, rotation RotateConfig) *Writer {
+	w := &Writer{
+		path:     path,
+		rotation: rotation,
+		lines:    make(chan []byte, queueSize),
+		flushes:  make(chan chan error),
+		done:     make(chan struct{}),
+		closed:   make(chan error, 1),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues one line; a trailing newline is added if missing. It blocks
+// only when the queue is full. Writes after Close are dropped.
+func (w *Writer) Write(line []byte) {
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+	select {
+	case <-w.done:
+	case w.lines <- line:
+	}
+}
+
+// Flush blocks until every line queued so far is on disk.
+func (w *Writer) Flush() error {
+	ack := make(chan error, 1)
+	select {
+	case <-w.done:
+		return nil
+	case w.flushes <- ack:
+		return <-ack
+	}
+}
+
+// Close writes every line queued before it and stops the writer
+// goroutine. Later calls return the same error.
+func (w *Writer) Close() error {
+	w.close.Do(func() {
+		close(w.done)
+		w.closeErr = <-w.closed
+	})
+	return w.closeErr
+}
+
+func (w *Writer) run() {
+	ticker := time.NewTicker(FlushInterval)
+	defer ticker.Stop()
+	var buf []byte
+	// failed holds the errors of background writes until they are
+	// reported by Flush or Close.
+	var failed []error
+	write := func() {
+		if len(buf) == 0 {
+			return
+		}
+		if err := w.writeBatch(buf); err != nil {
+			failed = append(failed, err)
+		}
+		buf = buf[:0]
+	}
+	report := func() error {
+		err := errors.Join(failed...)
+		failed = nil
+		return err
+	}
+	drain := func() {
+		for {
+			select {
+			case line := <-w.lines:
+				buf = append(buf, line...)
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case line := <-w.lines:
+			buf = append(buf, line...)
+			if len(buf) >= maxBuffered {
+				write()
+			}
+		case <-ticker.C:
+			write()
+		case ack := <-w.flushes:
+			// Drain lines that were queued before the flush request.
+			drain()
+			write()
+			ack <- report()
+		case <-w.done:
+			drain()
+			write()
+			w.closed <- report()
+			return
+		}
+	}
+}
+
+func (w *Writer) writeBatch(buf []byte) error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(w.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// A failed rotation is reported, but the lines are still written to
+	// the current file.
+	var rotateErr error
+	if NeedsRotation(w.path, w.rotation) {
+		_, rotateErr = rotate(w.path, w.rotation)
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Join(rotateErr, err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return errors.Join(rotateErr, err)
+	}
+	return errors.Join(rotateErr, f.Close())
+}
@@ -0,0 +1,178 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestWriterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "Tune.log")
+	w := NewWriter(path, RotateConfig{})
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 250 {
+				w.Write(fmt.Appendf(nil, "g%d line %d", g, i))
+			}
+		}()
+	}
+	wg.Wait()
+	// Every line queued before Close is written, with nothing in between.
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(readLines(t, path)); got != 1000 {
+		t.Errorf("wrote %d lines, want 1000", got)
+	}
+	// The state directory it creates is private to the user.
+	fi, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o700 {
+		t.Errorf("log directory mode = %s, want 0700", fi.Mode().Perm())
+	}
+
+	w.Write([]byte("after close"))
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() after Close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close(): %v", err)
+	}
+	if got := len(readLines(t, path)); got != 1000 {
+		t.Errorf("%d lines after writing to a closed writer, want 1000", got)
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tune.log")
+	w := NewWriter(path, RotateConfig{})
+	defer w.Close()
+	w.Write([]byte("one"))
+	w.Write([]byte("two\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, path); len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	// The log's directory is a file, so no write can succeed.
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "Tune.log")
+
+	w := NewWriter(path, RotateConfig{})
+	w.Write([]byte("lost"))
+	if err := w.Flush(); err == nil {
+		t.Error("Flush() reported no error")
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() with nothing pending: %v", err)
+	}
+
+	// A batch written in the background, here because it filled the
+	// buffer, reports its failure on the next Flush.
+	w.Write(bytes.Repeat([]byte("x"), maxBuffered))
+	if err := w.Flush(); err == nil {
+		t.Error("Flush() did not report the failed background write")
+	}
+
+	w.Write([]byte("lost on close"))
+	if err := w.Close(); err == nil {
+		t.Error("Close() reported no error")
+	}
+}
+
+func TestWriterRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tune.log")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("old\n"), 1<<18), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWriter(path, RotateConfig{MaxSizeMB: 1})
+	w.Write([]byte("new"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, path); len(got) != 1 || got[0] != "new" {
+		t.Errorf("log after rotation = %d lines, want only the new one", len(got))
+	}
+	archives := Archives(path)
+	if len(archives) != 1 {
+		t.Fatalf("archives = %v", archives)
+	}
+	if fi, err := os.Stat(archives[0]); err != nil || fi.Size() != 1<<20 {
+		t.Errorf("archive %s: %v", archives[0], err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tune.log")
+	if archive, err := Rotate(path, RotateConfig{}); archive != "" || err != nil {
+		t.Errorf("Rotate() without a log = %q, %v", archive, err)
+	}
+	cfg := RotateConfig{Compress: true, Retain: 2}
+	for i := range 3 {
+		if err := os.WriteFile(path, fmt.Appendf(nil, "log %d\n", i), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		archive, err := Rotate(path, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(archive, ".gz") {
+			t.Errorf("archive %q is not compressed", archive)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("log still present after rotation: %v", err)
+	}
+	if got := Archives(path); len(got) != 2 {
+		t.Errorf("archives = %v, want 2 retained", got)
+	}
+}
+
+func TestNeedsRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tune.log")
+	old := `{"ts":"2020-01-02T03:04:05Z","level":"INFO","event":"mode","msg":"start"}` + "\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cfg  RotateConfig
+		want bool
+	}{
+		{RotateConfig{}, false},
+		{RotateConfig{MaxSizeMB: 1}, false},
+		{RotateConfig{MaxAgeDays: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := NeedsRotation(path, tt.cfg); got != tt.want {
+			t.Errorf("NeedsRotation(%+v) = %v, want %v", tt.cfg, got, tt.want)
+		}
+	}
+	if NeedsRotation(path+".missing", RotateConfig{MaxAgeDays: 1}) {
+		t.Error("a missing log needs rotation")
+	}
+}
@@ -0,0 +1,172 @@
+// Package history keeps a local record of every fine-tune job submitted from
+// the TUI so that runs can be compared and reported on after the trainer
+// backend has forgotten them.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hwinfo"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+// Job statuses. Running, finished and error mirror the trainer's /status
+// endpoint; submitted is recorded before the first status poll and lost
+// when the trainer no longer knows the job, e.g. after a restart.
+const (
+	StatusSubmitted = "submitted"
+	StatusRunning   = "running"
+	StatusFinished  = "finished"
+	StatusError     = "error"
+	StatusLost      = "lost"
+)
+
+// ErrNotFound is returned when no job matches an ID.
+var ErrNotFound = errors.New("job not found")
+
+// Job is one fine-tune job as submitted to the trainer backend.
+type Job struct {
+	ID          string    `json:"id"`
+	Model       string    `json:"model"`
+	Dataset     string    `json:"dataset"`
+	Output      string    `json:"output"`
+	Local       bool      `json:"local"`
+	Status      string    `json:"status"`
+	LogPath     string    `json:"log_path"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	FinishedAt  time.Time `json:"finished_at,omitzero"`
+	// Profile is the token profile whose identity ran the job.
+	Profile string `json:"profile,omitempty"`
+
+	// Hardware is the submitting machine at submission time.
+	Hardware *hwinfo.Snapshot `json:"hardware,omitempty"`
+}
+
+// Done reports whether the job has reached a terminal status.
+func (j Job) Done() bool {
+	return j.Status == StatusFinished || j.Status == StatusError || j.Status == StatusLost
+}
+
+// Duration returns how long the job ran, or has been running so far.
+func (j Job) Duration() time.Duration {
+	if j.FinishedAt.IsZero() {
+		return time.Since(j.SubmittedAt)
+	}
+	return j.FinishedAt.Sub(j.SubmittedAt)
+}
+
+// Store is a JSON file holding the job history.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns the history file location in the XDG data directory.
+func DefaultPath() string {
+	return filepath.Join(xdg.DataHome(), "history.json")
+}
+
+// Open returns a store backed by the file at path. The file is created on
+// the first write.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// List returns all jobs, most recently submitted first.
+func (s *Store) List() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Get returns the job with the given ID. A unique ID prefix is accepted.
+func (s *Store) Get(id string) (Job, error) {
+	jobs, err := s.List()
+	if err != nil {
+		return Job{}, err
+	}
+	var found []Job
+	for _, j := range jobs {
+		if j.ID == id {
+			return j, nil
+		}
+		if strings.HasPrefix(j.ID, id) {
+			found = append(found, j)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Job{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	case 1:
+		return found[0], nil
+	}
+	return Job{}, fmt.Errorf("job ID prefix %q is ambiguous (%d matches)", id, len(found))
+}
+
+// Add records a newly submitted job.
+func (s *Store) Add(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return err
+	}
+	return s.save(append(jobs, j))
+}
+
+// Update applies fn to the job with the given ID and saves the result.
+func (s *Store) Update(id string, fn func(*Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if jobs[i].ID == id {
+			fn(&jobs[i])
+			return s.save(jobs)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+func (s *Store) load() ([]Job, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].SubmittedAt.After(jobs[j].SubmittedAt) })
+	return jobs, nil
+}
+
+// save writes the history atomically so a crash never leaves a torn file.
+func (s *Store) save(jobs []Job) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hwinfo"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "history.json")
+	s := Open(path)
+	if jobs, err := s.List(); err != nil || len(jobs) != 0 {
+		t.Fatalf("List() of a missing file = %v, %v", jobs, err)
+	}
+
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []Job{
+		{ID: "a1b2", Model: "gpt2", Dataset: "imdb", Output: "run-a", Status: StatusFinished,
+			LogPath: "/out/train_a1b2.log", SubmittedAt: t0, FinishedAt: t0.Add(time.Hour), Profile: "work",
+			Hardware: &hwinfo.Snapshot{Hostname: "gpu-box", OS: "linux", Arch: "amd64", CPUCores: 16,
+				GPUs: []hwinfo.GPU{{Name: "A100"}}, TakenAt: t0}},
+		{ID: "c3d4", Model: "mistral-7b", Dataset: "local.jsonl", Output: "run-c", Local: true,
+			Status: StatusRunning, SubmittedAt: t0.Add(2 * time.Hour)},
+		{ID: "a1ff", Model: "gpt2", Status: StatusError, SubmittedAt: t0.Add(time.Hour), FinishedAt: t0.Add(90 * time.Minute)},
+	}
+	for _, j := range jobs {
+		if err := s.Add(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A fresh store reads back the same jobs, newest first.
+	got, err := Open(path).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Job{jobs[1], jobs[2], jobs[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if j, err := s.Get("c3"); err != nil || j.ID != "c3d4" {
+		t.Errorf("Get(prefix) = %+v, %v", j, err)
+	}
+	if j, err := s.Get("a1b2"); err != nil || j.Hardware.GPUs[0].Name != "A100" {
+		t.Errorf("Get(id) = %+v, %v", j, err)
+	}
+	if _, err := s.Get("a1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(ambiguous prefix) error = %v", err)
+	}
+	if _, err := s.Get("zz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(unknown) error = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Update("c3d4", func(j *Job) { j.Status = StatusFinished }); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := Open(path).Get("c3d4"); j.Status != StatusFinished || j.Model != "mistral-7b" {
+		t.Errorf("job after Update = %+v", j)
+	}
+	if err := s.Update("zz", func(*Job) {}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte(`[{"id": "a1`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := Open(path)
+	if _, err := s.List(); err == nil {
+		t.Error("List() of a corrupt file succeeded")
+	}
+	// A corrupt history is not overwritten by the next job.
+	if err := s.Add(Job{ID: "b2"}); err == nil {
+		t.Error("Add() over a corrupt file succeeded")
+	}
+}
+
+func TestJobDuration(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	j := Job{Status: StatusFinished, SubmittedAt: t0, FinishedAt: t0.Add(90 * time.Minute)}
+	if !j.Done() || j.Duration() != 90*time.Minute {
+		t.Errorf("finished job: Done() = %v, Duration() = %s", j.Done(), j.Duration())
+	}
+	running := Job{Status: StatusRunning, SubmittedAt: time.Now().Add(-time.Minute)}
+	if running.Done() || running.Duration() < time.Minute {
+		t.Errorf("running job: Done() = %v, Duration() = %s", running.Done(), running.Duration())
+	}
+}
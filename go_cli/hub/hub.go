@@ -0,0 +1,125 @@
+// Package hub publishes adapters to the Hugging Face Hub, or to any server
+// implementing the same HTTP API, using the Hub's preupload, Git LFS batch
+// and commit endpoints.
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is returned when the hub rejects the token.
+	ErrUnauthorized = errors.New("hub: token rejected")
+	// ErrForbidden is returned when the token is valid but lacks the
+	// permission a request needs, such as write access to a repository.
+	ErrForbidden = errors.New("hub: token lacks permission for this request")
+)
+
+// Client calls the hub at BaseURL with a user access token.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+	// ResumeDir, when set, records the parts of multipart uploads so an
+	// interrupted upload resumes from its first missing part.
+	ResumeDir string
+}
+
+// NewClient returns a client for the hub at baseURL.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// RepoURL returns the browsable URL of a model repository.
+func (c *Client) RepoURL(repoID string) string {
+	return c.BaseURL + "/" + repoID
+}
+
+// SplitRepoID splits "org/name" into its namespace and name.
+func SplitRepoID(repoID string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(repoID, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid repo ID %q, want org/name", repoID)
+	}
+	return namespace, name, nil
+}
+
+// CreateRepo creates the model repository repoID. An already existing
+// repository is not an error.
+func (c *Client) CreateRepo(ctx context.Context, repoID string, private bool) error {
+	namespace, name, err := SplitRepoID(repoID)
+	if err != nil {
+		return err
+	}
+	body := map[string]any{"name": name, "organization": namespace, "private": private}
+	resp, err := c.do(ctx, http.MethodPost, c.BaseURL+"/api/repos/create", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return nil
+	}
+	return checkStatus(resp)
+}
+
+// do sends a request with the token attached. body is JSON-encoded unless
+// it is already an io.Reader.
+func (c *Client) do(ctx context.Context, method, url, contentType string, body any) (*http.Response, error) {
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		r = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return c.HTTP.Do(req)
+}
+
+// checkStatus turns a non-2xx hub response into an error.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w (%d %s)", ErrUnauthorized, resp.StatusCode, resp.Request.URL.Path)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w (%d %s)", ErrForbidden, resp.StatusCode, resp.Request.URL.Path)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		return fmt.Errorf("hub: %s (%d %s)", body.Error, resp.StatusCode, resp.Request.URL.Path)
+	}
+	return fmt.Errorf("hub: unexpected status code %d from %s", resp.StatusCode, resp.Request.URL.Path)
+}
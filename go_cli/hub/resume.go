@@ -0,0 +1,71 @@
+package hub
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// partState records the parts of a multipart upload the hub has accepted,
+// so that an interrupted upload continues after the last of them.
+type partState struct {
+	// Href is the completion URL, which identifies the hub's multipart
+	// upload; parts of an upload the hub has since replaced are useless.
+	Href  string   `json:"href"`
+	ETags []string `json:"etags"` // of parts 1 to len(ETags)
+}
+
+// statePath returns where the parts of oid are recorded, or "" when the
+// client does not resume uploads.
+func (c *Client) statePath(oid string) string {
+	if c.ResumeDir == "" {
+		return ""
+	}
+	return filepath.Join(c.ResumeDir, oid+".json")
+}
+
+// loadParts returns the parts already sent for oid within the multipart
+// upload completed at href. Unreadable state counts as none.
+func (c *Client) loadParts(oid, href string) []string {
+	path := c.statePath(oid)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var st partState
+	if json.Unmarshal(data, &st) != nil || st.Href != href {
+		return nil
+	}
+	return st.ETags
+}
+
+// saveParts records the parts sent so far. Failing to record them only
+// costs a resend, so errors are ignored.
+func (c *Client) saveParts(oid, href string, etags []string) {
+	path := c.statePath(oid)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(partState{Href: href, ETags: etags})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.ResumeDir, 0o700); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
+
+// clearParts forgets the parts of a completed upload.
+func (c *Client) clearParts(oid string) {
+	if path := c.statePath(oid); path != "" {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,455 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// sampleSize is how much of each file the preupload call inspects.
+	sampleSize = 512
+	// partRetries is how often a failed multipart chunk is retried.
+	partRetries  = 3
+	lfsMediaType = "application/vnd.git-lfs+json"
+)
+
+// Progress reports the state of an upload.
+type Progress struct {
+	File      string // path of the file being transferred
+	FileIndex int    // 1-based index of the file
+	FileCount int
+	Sent      int64 // bytes of File sent so far
+	Size      int64 // size of File
+	Skipped   bool  // the hub already had the file's content
+}
+
+// localFile is one file queued for upload.
+type localFile struct {
+	abs, path string // absolute path and path inside the repo
+	size      int64
+	oid       string // sha256 of the content
+	lfs       bool
+}
+
+// UploadDir uploads every file below dir, except intermediate trainer
+// checkpoints, to repoID in a single commit on the main branch.
+//
+// Large files go through Git LFS and multipart chunks are retried
+// individually. Content the hub already stores is skipped, so rerunning an
+// interrupted upload does not resend the files that were completed. With
+// ResumeDir set, a file that was cut off continues from its first missing
+// part as long as the hub hands out the same multipart upload again.
+func (c *Client) UploadDir(ctx context.Context, repoID, dir, summary string, progress func(Progress)) (string, error) {
+	if progress == nil {
+		progress = func(Progress) {}
+	}
+	files, err := collectFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files to upload in %s", dir)
+	}
+	if err := c.preupload(ctx, repoID, files); err != nil {
+		return "", err
+	}
+	for i := range files {
+		f := &files[i]
+		p := Progress{File: f.path, FileIndex: i + 1, FileCount: len(files), Size: f.size}
+		if !f.lfs {
+			p.Sent = f.size
+			progress(p)
+			continue
+		}
+		if err := c.uploadLFS(ctx, repoID, f, p, progress); err != nil {
+			return "", fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+	return c.commit(ctx, repoID, summary, files)
+}
+
+func collectFiles(dir string) ([]localFile, error) {
+	var files []localFile
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && (strings.HasPrefix(d.Name(), "checkpoint-") || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		oid, size, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, localFile{abs: p, path: filepath.ToSlash(rel), size: size, oid: oid})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, err
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func readSample(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, sampleSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf[:n]), nil
+}
+
+// preupload asks the hub which files must go through LFS.
+func (c *Client) preupload(ctx context.Context, repoID string, files []localFile) error {
+	type entry struct {
+		Path   string `json:"path"`
+		Sample string `json:"sample"`
+		Size   int64  `json:"size"`
+	}
+	req := struct {
+		Files []entry `json:"files"`
+	}{}
+	for _, f := range files {
+		sample, err := readSample(f.abs)
+		if err != nil {
+			return err
+		}
+		req.Files = append(req.Files, entry{Path: f.path, Sample: sample, Size: f.size})
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.BaseURL+"/api/models/"+repoID+"/preupload/main", "application/json", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	var out struct {
+		Files []struct {
+			Path       string `json:"path"`
+			UploadMode string `json:"uploadMode"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("hub: preupload response: %w", err)
+	}
+	modes := map[string]string{}
+	for _, f := range out.Files {
+		modes[f.Path] = f.UploadMode
+	}
+	for i := range files {
+		files[i].lfs = modes[files[i].path] == "lfs"
+	}
+	return nil
+}
+
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+// uploadLFS negotiates an upload for one file through the LFS batch API and
+// transfers it, in parts when the hub asks for multipart.
+func (c *Client) uploadLFS(ctx context.Context, repoID string, f *localFile, p Progress, progress func(Progress)) error {
+	batch := map[string]any{
+		"operation": "upload",
+		"transfers": []string{"basic", "multipart"},
+		"objects":   []map[string]any{{"oid": f.oid, "size": f.size}},
+		"hash_algo": "sha256",
+		"ref":       map[string]string{"name": "main"},
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/"+repoID+".git/info/lfs/objects/batch", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	var out struct {
+		Objects []struct {
+			Actions map[string]lfsAction `json:"actions"`
+			Error   *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("hub: LFS batch response: %w", err)
+	}
+	if len(out.Objects) != 1 {
+		return fmt.Errorf("hub: LFS batch returned %d objects", len(out.Objects))
+	}
+	obj := out.Objects[0]
+	if obj.Error != nil {
+		return fmt.Errorf("hub: %s", obj.Error.Message)
+	}
+	upload, ok := obj.Actions["upload"]
+	if !ok {
+		// The hub already has this content.
+		p.Sent, p.Skipped = f.size, true
+		progress(p)
+		return nil
+	}
+
+	if _, multipart := upload.Header["chunk_size"]; multipart {
+		err = c.uploadParts(ctx, f, upload, p, progress)
+	} else {
+		err = c.uploadBasic(ctx, f, upload, p, progress)
+	}
+	if err != nil {
+		return err
+	}
+	if verify, ok := obj.Actions["verify"]; ok {
+		return c.lfsVerify(ctx, f, verify)
+	}
+	return nil
+}
+
+func (c *Client) uploadBasic(ctx context.Context, f *localFile, action lfsAction, p Progress, progress func(Progress)) error {
+	file, err := os.Open(f.abs)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	body := &progressReader{r: file, p: p, fn: progress}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, action.Href, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = f.size
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp)
+}
+
+// uploadParts sends the file in chunk_size pieces to the numbered part URLs
+// and completes the multipart upload with the collected ETags. Parts
+// recorded by an earlier attempt are not sent again.
+func (c *Client) uploadParts(ctx context.Context, f *localFile, action lfsAction, p Progress, progress func(Progress)) error {
+	chunkSize, err := strconv.ParseInt(action.Header["chunk_size"], 10, 64)
+	if err != nil || chunkSize <= 0 {
+		return fmt.Errorf("hub: invalid chunk_size %q", action.Header["chunk_size"])
+	}
+	file, err := os.Open(f.abs)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	type part struct {
+		PartNumber int    `json:"partNumber"`
+		ETag       string `json:"etag"`
+	}
+	var parts []part
+	etags := c.loadParts(f.oid, action.Href)
+	for n := 1; int64(n-1)*chunkSize < f.size; n++ {
+		offset := int64(n-1) * chunkSize
+		length := min(chunkSize, f.size-offset)
+		if n <= len(etags) {
+			p.Sent = offset + length
+			progress(p)
+			parts = append(parts, part{PartNumber: n, ETag: etags[n-1]})
+			continue
+		}
+		url, ok := action.Header[strconv.Itoa(n)]
+		if !ok {
+			return fmt.Errorf("hub: no URL for part %d", n)
+		}
+		var etag string
+		for attempt := 1; ; attempt++ {
+			p.Sent = offset
+			etag, err = c.putPart(ctx, url, io.NewSectionReader(file, offset, length), length, p, progress)
+			if err == nil || attempt == partRetries || ctx.Err() != nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("part %d: %w", n, err)
+		}
+		parts = append(parts, part{PartNumber: n, ETag: etag})
+		etags = append(etags[:n-1], etag)
+		c.saveParts(f.oid, action.Href, etags)
+	}
+
+	// The completion URL comes from the hub and may point elsewhere, so it
+	// gets the action's own headers rather than the token.
+	data, err := json.Marshal(map[string]any{"oid": f.oid, "parts": parts})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action.Href, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", lfsMediaType)
+	for k, v := range action.Header {
+		if _, err := strconv.Atoi(k); err == nil || k == "chunk_size" {
+			continue // part URLs and the chunk size are not headers
+		}
+		req.Header.Set(k, v)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	c.clearParts(f.oid)
+	return nil
+}
+
+func (c *Client) putPart(ctx context.Context, url string, r io.Reader, length int64, p Progress, progress func(Progress)) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, &progressReader{r: r, p: p, fn: progress})
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = length
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) lfsVerify(ctx context.Context, f *localFile, action lfsAction) error {
+	data, err := json.Marshal(map[string]any{"oid": f.oid, "size": f.size})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action.Href, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", lfsMediaType)
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp)
+}
+
+// commit creates a commit on main referencing the LFS objects and carrying
+// the small files inline.
+func (c *Client) commit(ctx context.Context, repoID, summary string, files []localFile) (string, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	if err := enc.Encode(map[string]any{"key": "header", "value": map[string]string{"summary": summary, "description": ""}}); err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		var line map[string]any
+		if f.lfs {
+			line = map[string]any{"key": "lfsFile", "value": map[string]string{"path": f.path, "algo": "sha256", "oid": f.oid}}
+		} else {
+			data, err := os.ReadFile(f.abs)
+			if err != nil {
+				return "", err
+			}
+			line = map[string]any{"key": "file", "value": map[string]string{
+				"path": f.path, "encoding": "base64", "content": base64.StdEncoding.EncodeToString(data),
+			}}
+		}
+		if err := enc.Encode(line); err != nil {
+			return "", err
+		}
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.BaseURL+"/api/models/"+repoID+"/commit/main", "application/x-ndjson", &body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return "", err
+	}
+	var out struct {
+		CommitURL string `json:"commitUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("hub: commit response: %w", err)
+	}
+	if out.CommitURL == "" {
+		out.CommitURL = c.RepoURL(repoID)
+	}
+	return out.CommitURL, nil
+}
+
+// progressReader reports bytes read through fn.
+type progressReader struct {
+	r  io.Reader
+	p  Progress
+	fn func(Progress)
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		pr.p.Sent += int64(n)
+		pr.fn(pr.p)
+	}
+	return n, err
+}
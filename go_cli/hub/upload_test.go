@@ -0,0 +1,378 @@
+package hub
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testRepo = "org/adapter"
+
+// fakeHub is a stand-in for the hub's upload endpoints. .bin and
+// .safetensors files go through LFS: those over 8 bytes in 4-byte parts,
+// smaller ones with a basic PUT, and content listed in stored is already on
+// the hub.
+type fakeHub struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu sync.Mutex
+	// stored is the LFS content the hub has, by oid.
+	stored map[string][]byte
+	// parts are the multipart chunks received, by part number.
+	parts map[string][]byte
+	// failParts makes the next PUTs of a part number fail with a 503.
+	failParts map[string]int
+	// puts counts the PUTs of each part number.
+	puts   map[string]int
+	commit []map[string]any
+	// commitBody replaces the commit response when set.
+	commitBody string
+}
+
+func newFakeHub(t *testing.T) *fakeHub {
+	h := &fakeHub{t: t, stored: map[string][]byte{}, parts: map[string][]byte{}, failParts: map[string]int{}, puts: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/models/org/adapter/preupload/main", h.preupload)
+	mux.HandleFunc("POST /org/adapter.git/info/lfs/objects/batch", h.batch)
+	mux.HandleFunc("PUT /basic/{oid}", h.putBasic)
+	mux.HandleFunc("POST /verify", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("PUT /part/{n}", h.putPart)
+	mux.HandleFunc("POST /complete/{oid}", h.complete)
+	mux.HandleFunc("POST /api/models/org/adapter/commit/main", h.commitFiles)
+	h.srv = httptest.NewServer(h.authorized(mux))
+	t.Cleanup(h.srv.Close)
+	return h
+}
+
+// authorized rejects hub API calls without the test token. Presigned
+// upload URLs carry no token, as on the real hub.
+func (h *fakeHub) authorized(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presigned := strings.HasPrefix(r.URL.Path, "/part/") || strings.HasPrefix(r.URL.Path, "/basic/")
+		if strings.HasPrefix(r.URL.Path, "/complete/") {
+			// The completion URL authenticates through the action's
+			// headers; the token must not leak to it.
+			if r.Header.Get("Authorization") != "" || r.Header.Get("X-Upload-Token") != "signed" {
+				h.t.Errorf("complete headers = %v", r.Header)
+				http.Error(w, `{"error": "forbidden"}`, http.StatusForbidden)
+				return
+			}
+			presigned = true
+		}
+		if !presigned && r.Header.Get("Authorization") != "Bearer hf_test" {
+			http.Error(w, `{"error": "unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *fakeHub) preupload(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Files []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.t.Errorf("preupload: %v", err)
+	}
+	type mode struct {
+		Path       string `json:"path"`
+		UploadMode string `json:"uploadMode"`
+	}
+	var out struct {
+		Files []mode `json:"files"`
+	}
+	for _, f := range req.Files {
+		m := "regular"
+		if strings.HasSuffix(f.Path, ".bin") || strings.HasSuffix(f.Path, ".safetensors") {
+			m = "lfs"
+		}
+		out.Files = append(out.Files, mode{f.Path, m})
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+func (h *fakeHub) batch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Objects []struct {
+			OID  string `json:"oid"`
+			Size int64  `json:"size"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Objects) != 1 {
+		h.t.Errorf("batch request: %v %+v", err, req)
+		return
+	}
+	obj := req.Objects[0]
+	h.mu.Lock()
+	_, stored := h.stored[obj.OID]
+	h.mu.Unlock()
+	actions := map[string]lfsAction{}
+	switch {
+	case stored:
+	case obj.Size > 8:
+		header := map[string]string{"chunk_size": "4", "X-Upload-Token": "signed"}
+		for n := 1; int64(n-1)*4 < obj.Size; n++ {
+			header[itoa(n)] = h.srv.URL + "/part/" + itoa(n)
+		}
+		actions["upload"] = lfsAction{Href: h.srv.URL + "/complete/" + obj.OID, Header: header}
+	default:
+		actions["upload"] = lfsAction{Href: h.srv.URL + "/basic/" + obj.OID}
+		actions["verify"] = lfsAction{Href: h.srv.URL + "/verify", Header: map[string]string{"Authorization": "Bearer hf_test"}}
+	}
+	w.Header().Set("Content-Type", lfsMediaType)
+	json.NewEncoder(w).Encode(map[string]any{"objects": []map[string]any{{"oid": obj.OID, "actions": actions}}})
+}
+
+func (h *fakeHub) putBasic(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stored[r.PathValue("oid")] = data
+}
+
+func (h *fakeHub) putPart(w http.ResponseWriter, r *http.Request) {
+	n := r.PathValue("n")
+	data, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.puts[n]++
+	if h.failParts[n] > 0 {
+		h.failParts[n]--
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	h.parts[n] = data
+	w.Header().Set("ETag", `"etag-`+n+`"`)
+}
+
+func (h *fakeHub) complete(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OID   string `json:"oid"`
+		Parts []struct {
+			PartNumber int    `json:"partNumber"`
+			ETag       string `json:"etag"`
+		} `json:"parts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.t.Errorf("complete: %v", err)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var data []byte
+	for i, p := range req.Parts {
+		if p.PartNumber != i+1 || p.ETag != `"etag-`+itoa(i+1)+`"` {
+			h.t.Errorf("part %d = %+v", i+1, p)
+		}
+		data = append(data, h.parts[itoa(p.PartNumber)]...)
+	}
+	h.stored[req.OID] = data
+}
+
+func (h *fakeHub) commitFiles(w http.ResponseWriter, r *http.Request) {
+	sc := bufio.NewScanner(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sc.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			h.t.Errorf("commit line %q: %v", sc.Text(), err)
+		}
+		h.commit = append(h.commit, line)
+	}
+	if h.commitBody != "" {
+		io.WriteString(w, h.commitBody)
+		return
+	}
+	io.WriteString(w, `{"commitUrl": "`+h.srv.URL+`/org/adapter/commit/abc"}`)
+}
+
+func itoa(n int) string {
+	return string(rune('0' + n))
+}
+
+func oid(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeRun(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUploadDir(t *testing.T) {
+	h := newFakeHub(t)
+	const (
+		weights = "0123456789abcdefghij" // 5 parts of 4 bytes
+		tokens  = "vocab"
+		cached  = "stored"
+	)
+	h.stored[oid(cached)] = []byte(cached)
+	h.failParts["2"] = 1
+	dir := writeRun(t, map[string]string{
+		"adapter_model.safetensors":    weights,
+		"tokenizer.bin":                tokens,
+		"training_args.bin":            cached,
+		"README.md":                    "# adapter\n",
+		"checkpoint-10/optimizer.bin":  "skipped",
+		".cache/huggingface/lock.json": "skipped",
+	})
+
+	var skipped []string
+	c := NewClient(h.srv.URL, "hf_test")
+	url, err := c.UploadDir(context.Background(), testRepo, dir, "Upload adapter", func(p Progress) {
+		if p.Skipped {
+			skipped = append(skipped, p.File)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != h.srv.URL+"/org/adapter/commit/abc" {
+		t.Errorf("commit URL = %q", url)
+	}
+	if got := string(h.stored[oid(weights)]); got != weights {
+		t.Errorf("multipart content = %q, want %q", got, weights)
+	}
+	if got := string(h.stored[oid(tokens)]); got != tokens {
+		t.Errorf("basic content = %q, want %q", got, tokens)
+	}
+	if len(skipped) != 1 || skipped[0] != "training_args.bin" {
+		t.Errorf("skipped = %v, want the stored file", skipped)
+	}
+
+	// The commit has its header, the inline README and three LFS pointers;
+	// checkpoints and hidden directories are left out.
+	want := map[string]string{
+		"README.md":                 "file",
+		"adapter_model.safetensors": "lfsFile",
+		"tokenizer.bin":             "lfsFile",
+		"training_args.bin":         "lfsFile",
+	}
+	if len(h.commit) != len(want)+1 || h.commit[0]["key"] != "header" {
+		t.Fatalf("commit = %v", h.commit)
+	}
+	for _, line := range h.commit[1:] {
+		value := line["value"].(map[string]any)
+		path := value["path"].(string)
+		if want[path] != line["key"] {
+			t.Errorf("commit %s as %v, want %s", path, line["key"], want[path])
+		}
+		if path == "README.md" {
+			content, _ := base64.StdEncoding.DecodeString(value["content"].(string))
+			if string(content) != "# adapter\n" {
+				t.Errorf("README content = %q", content)
+			}
+		}
+	}
+}
+
+func TestUploadResumes(t *testing.T) {
+	const weights = "0123456789abcdefghij" // 5 parts of 4 bytes
+	dir := writeRun(t, map[string]string{"adapter_model.safetensors": weights})
+
+	h := newFakeHub(t)
+	h.failParts["3"] = partRetries
+	c := NewClient(h.srv.URL, "hf_test")
+	c.ResumeDir = t.TempDir()
+	if _, err := c.UploadDir(context.Background(), testRepo, dir, "Upload", nil); err == nil {
+		t.Fatal("upload with a failing part succeeded")
+	}
+	state := c.statePath(oid(weights))
+	if _, err := os.Stat(state); err != nil {
+		t.Fatalf("no resume state after the failure: %v", err)
+	}
+
+	// The rerun sends only part 3 onwards.
+	if _, err := c.UploadDir(context.Background(), testRepo, dir, "Upload", nil); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"1": 1, "2": 1, "3": partRetries + 1, "4": 1, "5": 1}
+	for n, count := range want {
+		if h.puts[n] != count {
+			t.Errorf("part %s sent %d times, want %d", n, h.puts[n], count)
+		}
+	}
+	if got := string(h.stored[oid(weights)]); got != weights {
+		t.Errorf("content = %q, want %q", got, weights)
+	}
+	if _, err := os.Stat(state); !os.IsNotExist(err) {
+		t.Errorf("resume state left after completion: %v", err)
+	}
+
+	// Parts recorded for another multipart upload are sent again.
+	h = newFakeHub(t)
+	c.BaseURL = h.srv.URL
+	if err := os.WriteFile(state, []byte(`{"href": "https://elsewhere/complete", "etags": ["\"stale\""]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UploadDir(context.Background(), testRepo, dir, "Upload", nil); err != nil {
+		t.Fatal(err)
+	}
+	if h.puts["1"] != 1 {
+		t.Errorf("part 1 of a replaced upload sent %d times, want 1", h.puts["1"])
+	}
+}
+
+func TestUploadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(h *fakeHub)
+		want  string
+	}{
+		{
+			name:  "part keeps failing",
+			token: "hf_test",
+			setup: func(h *fakeHub) { h.failParts["3"] = partRetries },
+			want:  "part 3",
+		},
+		{
+			name:  "malformed commit response",
+			token: "hf_test",
+			setup: func(h *fakeHub) { h.commitBody = "<html>oops</html>" },
+			want:  "commit response",
+		},
+		{
+			name:  "rejected token",
+			token: "hf_wrong",
+			setup: func(h *fakeHub) {},
+			want:  ErrUnauthorized.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFakeHub(t)
+			tt.setup(h)
+			dir := writeRun(t, map[string]string{"adapter_model.safetensors": "0123456789abcdefghij", "README.md": "x"})
+			_, err := NewClient(h.srv.URL, tt.token).UploadDir(context.Background(), testRepo, dir, "Upload", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Token roles reported by the hub.
+const (
+	RoleRead        = "read"
+	RoleWrite       = "write"
+	RoleFineGrained = "fineGrained"
+)
+
+// Identity is the account behind a token, as reported by whoami-v2.
+type Identity struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Orgs      []string `json:"orgs,omitempty"`
+	TokenName string   `json:"token_name,omitempty"`
+	Role      string   `json:"role"`
+	// Permissions lists fine-grained permissions such as "repo.write"
+	// across every scope of the token.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// CanWrite reports whether the token may create and push to repositories.
+func (id Identity) CanWrite() bool {
+	switch id.Role {
+	case RoleWrite:
+		return true
+	case RoleFineGrained:
+		return slices.Contains(id.Permissions, "repo.write")
+	}
+	return false
+}
+
+// Scope describes the token's access for display: "read", "write" or
+// "fine-grained (write)".
+func (id Identity) Scope() string {
+	switch id.Role {
+	case RoleFineGrained:
+		if id.CanWrite() {
+			return "fine-grained (write)"
+		}
+		return "fine-grained (read)"
+	case "":
+		return "unknown"
+	}
+	return id.Role
+}
+
+// String summarizes the identity on one line.
+func (id Identity) String() string {
+	s := id.Name + " · " + id.Scope() + " token"
+	if len(id.Orgs) > 0 {
+		s += " · orgs: " + strings.Join(id.Orgs, ", ")
+	}
+	return s
+}
+
+// WhoAmI asks the hub which account the client's token belongs to. An
+// invalid or expired token yields ErrUnauthorized, one the hub refuses to
+// describe ErrForbidden.
+func (c *Client) WhoAmI(ctx context.Context) (Identity, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.BaseURL+"/api/whoami-v2", "", nil)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return Identity{}, err
+	}
+	var body struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Orgs []struct {
+			Name string `json:"name"`
+		} `json:"orgs"`
+		Auth struct {
+			AccessToken struct {
+				DisplayName string `json:"displayName"`
+				Role        string `json:"role"`
+				FineGrained struct {
+					Global []string `json:"global"`
+					Scoped []struct {
+						Permissions []string `json:"permissions"`
+					} `json:"scoped"`
+				} `json:"fineGrained"`
+			} `json:"accessToken"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Identity{}, fmt.Errorf("hub: decoding whoami response: %w", err)
+	}
+	token := body.Auth.AccessToken
+	id := Identity{Name: body.Name, Type: body.Type, TokenName: token.DisplayName, Role: token.Role}
+	for _, o := range body.Orgs {
+		id.Orgs = append(id.Orgs, o.Name)
+	}
+	id.Permissions = append(id.Permissions, token.FineGrained.Global...)
+	for _, s := range token.FineGrained.Scoped {
+		for _, p := range s.Permissions {
+			if !slices.Contains(id.Permissions, p) {
+				id.Permissions = append(id.Permissions, p)
+			}
+		}
+	}
+	return id, nil
+}
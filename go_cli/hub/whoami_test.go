@@ -0,0 +1,103 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantWrite bool
+		wantScope string
+		wantOrgs  []string
+	}{
+		{
+			name:   "write token",
+			status: http.StatusOK,
+			body: `{"name": "ada", "type": "user", "orgs": [{"name": "nexa"}],
+				"auth": {"accessToken": {"displayName": "laptop", "role": "write"}}}`,
+			wantWrite: true,
+			wantScope: "write",
+			wantOrgs:  []string{"nexa"},
+		},
+		{
+			name:      "read token",
+			status:    http.StatusOK,
+			body:      `{"name": "ada", "type": "user", "auth": {"accessToken": {"role": "read"}}}`,
+			wantScope: "read",
+		},
+		{
+			name:   "fine-grained token with repo write in a scope",
+			status: http.StatusOK,
+			body: `{"name": "ada", "type": "user", "auth": {"accessToken": {"role": "fineGrained",
+				"fineGrained": {"global": ["discussion.write"], "scoped": [
+					{"entity": {"type": "org", "name": "nexa"}, "permissions": ["repo.content.read", "repo.write"]}]}}}}`,
+			wantWrite: true,
+			wantScope: "fine-grained (write)",
+		},
+		{
+			name:   "fine-grained read-only token",
+			status: http.StatusOK,
+			body: `{"name": "ada", "type": "user", "auth": {"accessToken": {"role": "fineGrained",
+				"fineGrained": {"global": ["discussion.write"], "scoped": [
+					{"entity": {"type": "user", "name": "ada"}, "permissions": ["repo.content.read"]}]}}}}`,
+			wantScope: "fine-grained (read)",
+		},
+		{
+			name:    "invalid token",
+			status:  http.StatusUnauthorized,
+			body:    `{"error": "Invalid credentials in Authorization header"}`,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "token without permission",
+			status:  http.StatusForbidden,
+			body:    `{"error": "Forbidden"}`,
+			wantErr: ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/whoami-v2" || r.Header.Get("Authorization") != "Bearer hf_test" {
+					t.Errorf("request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			id, err := NewClient(srv.URL, "hf_test").WhoAmI(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id.Name != "ada" {
+				t.Errorf("Name = %q", id.Name)
+			}
+			if got := id.CanWrite(); got != tt.wantWrite {
+				t.Errorf("CanWrite() = %v, want %v", got, tt.wantWrite)
+			}
+			if got := id.Scope(); got != tt.wantScope {
+				t.Errorf("Scope() = %q, want %q", got, tt.wantScope)
+			}
+			if !slices.Equal(id.Orgs, tt.wantOrgs) {
+				t.Errorf("Orgs = %v, want %v", id.Orgs, tt.wantOrgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package hwinfo captures a best-effort snapshot of the machine a job was
+// submitted from. Every field is optional; probes that fail are skipped.
+package hwinfo
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPU is one accelerator reported by nvidia-smi.
+type GPU struct {
+	Name     string `json:"name"`
+	MemoryMB int    `json:"memory_mb"`
+	Driver   string `json:"driver,omitempty"`
+}
+
+// Snapshot describes the host at a point in time.
+type Snapshot struct {
+	Hostname      string    `json:"hostname"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	CPUModel      string    `json:"cpu_model,omitempty"`
+	CPUCores      int       `json:"cpu_cores"`
+	MemoryTotalMB int       `json:"memory_total_mb,omitempty"`
+	GPUs          []GPU     `json:"gpus,omitempty"`
+	TakenAt       time.Time `json:"taken_at"`
+}
+
+// Take probes the local machine.
+func Take() *Snapshot {
+	s := &Snapshot{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		CPUCores: runtime.NumCPU(),
+		TakenAt:  time.Now(),
+	}
+	s.Hostname, _ = os.Hostname()
+	s.CPUModel = procField("/proc/cpuinfo", "model name")
+	if mem := procField("/proc/meminfo", "MemTotal"); mem != "" {
+		kb, _ := strconv.Atoi(strings.TrimSuffix(mem, " kB"))
+		s.MemoryTotalMB = kb / 1024
+	}
+	s.GPUs = nvidiaGPUs()
+	return s
+}
+
+// procField returns the value of the first "key: value" line in a /proc
+// file, or "" when unavailable.
+func procField(path, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func nvidiaGPUs() []GPU {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=name,memory.total,driver_version", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil
+	}
+	var gpus []GPU
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 {
+			continue
+		}
+		mem, _ := strconv.Atoi(strings.TrimSpace(fields[1]))
+		gpus = append(gpus, GPU{
+			Name:     strings.TrimSpace(fields[0]),
+			MemoryMB: mem,
+			Driver:   strings.TrimSpace(fields[2]),
+		})
+	}
+	return gpus
+}
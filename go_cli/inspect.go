@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/safetensors"
+)
+
+// outputRoot is where the trainer backend writes adapters and job logs.
+const outputRoot = "nexa_output"
+
+// --- Inspect Command ---
+func runInspect(args []string) error {
+	fset := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	asJSON := fset.Bool("json", false, "print headers as JSON")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		return errors.New("usage: nexa inspect [--json] <path>...")
+	}
+
+	var files []string
+	for _, p := range fset.Args() {
+		found, err := findSafetensors(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, found...)
+	}
+	if len(files) == 0 {
+		return errors.New("no .safetensors files found")
+	}
+
+	var headers []*safetensors.Header
+	failed := 0
+	for _, f := range files {
+		h, err := safetensors.ReadHeader(f)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+			continue
+		}
+		headers = append(headers, h)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(headers); err != nil {
+			return err
+		}
+	} else {
+		for i, h := range headers {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(formatHeader(h))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed validation", failed, len(files))
+	}
+	return nil
+}
+
+// findSafetensors returns path itself for a file, or every .safetensors
+// file below it for a directory.
+func findSafetensors(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".safetensors") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// formatHeader renders a safetensors header as a plain-text table. It is
+// shared by the inspect command and the artifact browser.
+func formatHeader(h *safetensors.Header) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "File:       %s\n", h.Path)
+	fmt.Fprintf(&b, "Size:       %s (header %s)\n", formatBytes(h.FileSize), formatBytes(h.HeaderSize))
+	fmt.Fprintf(&b, "Tensors:    %d\n", len(h.Tensors))
+	fmt.Fprintf(&b, "Parameters: %s\n", formatCount(h.ParamCount()))
+	if len(h.Metadata) > 0 {
+		b.WriteString("Metadata:\n")
+		for _, k := range slices.Sorted(maps.Keys(h.Metadata)) {
+			fmt.Fprintf(&b, "  %s = %s\n", k, h.Metadata[k])
+		}
+	}
+	b.WriteString("\n")
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDTYPE\tSHAPE\tBYTES")
+	for _, t := range h.Tensors {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.Name, t.DType, formatShape(t.Shape), formatBytes(t.Size()))
+	}
+	tw.Flush()
+	return b.String()
+}
+
+func formatShape(shape []int64) string {
+	parts := make([]string, len(shape))
+	for i, d := range shape {
+		parts[i] = fmt.Sprint(d)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatCount(n int64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.2fB (%d)", float64(n)/1e9, n)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.2fM (%d)", float64(n)/1e6, n)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK (%d)", float64(n)/1e3, n)
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hwinfo"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/notify"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+const jobPollInterval = 5 * time.Second
+
+// jobHistory records every job submitted from this machine.
+var jobHistory = history.Open(history.DefaultPath())
+
+// --- Job Messages ---
+type jobSubmittedMsg history.Job
+
+type jobStatusMsg struct {
+	id     string
+	status string
+	err    error
+}
+
+// recordJob stores a freshly submitted job in the history.
+func recordJob(req TrainRequest, id, profile string) history.Job {
+	job := history.Job{
+		ID:          id,
+		Model:       req.Model,
+		Dataset:     req.Dataset,
+		Output:      req.Output,
+		Local:       req.Local,
+		Profile:     profile,
+		Status:      history.StatusSubmitted,
+		LogPath:     filepath.Join(outputRoot, "train_"+id+".log"),
+		SubmittedAt: time.Now(),
+		Hardware:    hwinfo.Take(),
+	}
+	if err := jobHistory.Add(job); err != nil {
+		logEvent(eventlog.Error, eventlog.TypeHistory, "Failed to record job in history", eventlog.Fields{"job_id": id, "error": err.Error()})
+	}
+	return job
+}
+
+// pollJobStatus asks the trainer for a job's status after jobPollInterval.
+func pollJobStatus(id string) tea.Cmd {
+	return tea.Tick(jobPollInterval, func(time.Time) tea.Msg {
+		status, err := fetchJobStatus(id)
+		return jobStatusMsg{id: id, status: status, err: err}
+	})
+}
+
+func fetchJobStatus(id string) (string, error) {
+	client := trainerClient()
+	resp, err := client.Get(context.Background(), "/status/"+id)
+	if err != nil {
+		trainerFailed(client)
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return history.StatusLost, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	return body.Status, nil
+}
+
+// applyJobStatus records a polled status and returns the updated job and
+// whether polling should continue.
+func applyJobStatus(msg jobStatusMsg) (history.Job, bool) {
+	var job history.Job
+	if msg.err != nil {
+		// The trainer may be restarting; keep polling.
+		return job, true
+	}
+	err := jobHistory.Update(msg.id, func(j *history.Job) {
+		if j.Status != msg.status {
+			j.Status = msg.status
+			if j.Done() {
+				j.FinishedAt = time.Now()
+			}
+		}
+		job = *j
+	})
+	if err != nil {
+		logEvent(eventlog.Error, eventlog.TypeHistory, "Failed to update job history", eventlog.Fields{"job_id": msg.id, "error": err.Error()})
+		return job, false
+	}
+	return job, !job.Done()
+}
+
+// jobNotifiedMsg carries the terminal escapes of a job notification, which
+// the TUI prints through the program so they do not tear its output.
+type jobNotifiedMsg struct {
+	escapes string
+	summary string
+}
+
+// notifyJobDone announces a finished or failed job through the configured
+// webhook and command hook, then hands the bell and desktop escapes back
+// to the program as a jobNotifiedMsg.
+func notifyJobDone(job history.Job) tea.Cmd {
+	cfg := appConfig.Notify
+	if !cfg.Enabled() || (job.Status != history.StatusFinished && job.Status != history.StatusError) {
+		return nil
+	}
+	return func() tea.Msg {
+		dir := filepath.Join(outputRoot, job.Output)
+		ev := notify.Event{JobID: job.ID, Status: job.Status, OutputDir: dir, Duration: job.Duration()}
+		if state, err := trainerstate.Find(dir); err == nil {
+			if v, ok := state.FinalLoss(); ok {
+				ev.FinalLoss = &v
+			}
+		}
+		if err := notify.New(cfg).Send(ev); err != nil {
+			logEvent(eventlog.Warn, eventlog.TypeNotify, "Notification failed", eventlog.Fields{"job_id": job.ID, "error": err.Error()})
+		}
+		if esc := cfg.Escapes(ev); esc != "" {
+			return jobNotifiedMsg{escapes: esc, summary: ev.Summary()}
+		}
+		return nil
+	}
+}
+
+// resumeJobPolling restarts status polling for jobs left unfinished by a
+// previous TUI session.
+func resumeJobPolling() tea.Cmd {
+	jobs, err := jobHistory.List()
+	if err != nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, j := range jobs {
+		if !j.Done() {
+			cmds = append(cmds, pollJobStatus(j.ID))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchJobLogs returns a job's trainer log, reading the local file when the
+// trainer shares this working directory and asking the trainer otherwise.
+func fetchJobLogs(job history.Job) (string, error) {
+	if data, err := os.ReadFile(job.LogPath); err == nil {
+		return string(data), nil
+	}
+	client := trainerClient()
+	resp, err := client.Get(context.Background(), "/logs/"+job.ID)
+	if err != nil {
+		trainerFailed(client)
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var body struct {
+		Logs string `json:"logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	return body.Logs, nil
+}
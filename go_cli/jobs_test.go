@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+)
+
+// tempJobHistory points the job history and event log at a temporary
+// directory for the duration of the test.
+func tempJobHistory(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	savedHistory, savedLog := jobHistory, eventLog
+	jobHistory = history.Open(filepath.Join(dir, "history.json"))
+	eventLog = eventlog.New(filepath.Join(dir, "Tune.log"), "test")
+	t.Cleanup(func() {
+		eventLog.Close()
+		jobHistory, eventLog = savedHistory, savedLog
+	})
+}
+
+func TestApplyJobStatus(t *testing.T) {
+	tempJobHistory(t)
+	submitted := time.Now().Add(-time.Hour)
+	if err := jobHistory.Add(history.Job{ID: "job-1", Model: "gpt2", Status: history.StatusSubmitted, SubmittedAt: submitted}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name        string
+		msg         jobStatusMsg
+		wantStatus  string
+		wantPending bool
+		wantDone    bool
+	}{
+		{"trainer unreachable", jobStatusMsg{id: "job-1", err: errors.New("connection refused")}, history.StatusSubmitted, true, false},
+		{"running", jobStatusMsg{id: "job-1", status: history.StatusRunning}, history.StatusRunning, true, false},
+		{"still running", jobStatusMsg{id: "job-1", status: history.StatusRunning}, history.StatusRunning, true, false},
+		{"finished", jobStatusMsg{id: "job-1", status: history.StatusFinished}, history.StatusFinished, false, true},
+	}
+	for _, s := range steps {
+		job, pending := applyJobStatus(s.msg)
+		if pending != s.wantPending {
+			t.Errorf("%s: pending = %v, want %v", s.name, pending, s.wantPending)
+		}
+		stored, err := jobHistory.Get("job-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != s.wantStatus {
+			t.Errorf("%s: stored status = %q, want %q", s.name, stored.Status, s.wantStatus)
+		}
+		if s.msg.err == nil && job.Status != s.wantStatus {
+			t.Errorf("%s: returned status = %q, want %q", s.name, job.Status, s.wantStatus)
+		}
+		if got := !stored.FinishedAt.IsZero(); got != s.wantDone {
+			t.Errorf("%s: finished at %v", s.name, stored.FinishedAt)
+		}
+	}
+
+	// A repeated terminal status keeps the original finish time.
+	first, _ := jobHistory.Get("job-1")
+	time.Sleep(time.Millisecond)
+	if _, pending := applyJobStatus(jobStatusMsg{id: "job-1", status: history.StatusFinished}); pending {
+		t.Error("finished job still pending")
+	}
+	if again, _ := jobHistory.Get("job-1"); !again.FinishedAt.Equal(first.FinishedAt) {
+		t.Errorf("FinishedAt moved from %v to %v", first.FinishedAt, again.FinishedAt)
+	}
+}
+
+func TestApplyJobStatusLostAndUnknown(t *testing.T) {
+	tempJobHistory(t)
+	if err := jobHistory.Add(history.Job{ID: "job-2", Status: history.StatusRunning, SubmittedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	// The trainer forgot the job, e.g. after a restart.
+	job, pending := applyJobStatus(jobStatusMsg{id: "job-2", status: history.StatusLost})
+	if pending || job.Status != history.StatusLost || job.FinishedAt.IsZero() {
+		t.Errorf("lost job = %+v, pending %v", job, pending)
+	}
+	// A job missing from the history stops being polled.
+	if _, pending := applyJobStatus(jobStatusMsg{id: "gone", status: history.StatusRunning}); pending {
+		t.Error("unknown job still pending")
+	}
+}
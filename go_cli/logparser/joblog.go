@@ -0,0 +1,259 @@
+package logparser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultJobRetention is how long the gauges of a finished job are kept.
+const DefaultJobRetention = 24 * time.Hour
+
+// Phases of a fine-tune job as announced in its trainer log.
+const (
+	PhaseLoadingModel   = "loading_model"
+	PhaseLoadingDataset = "loading_dataset"
+	PhaseTraining       = "training"
+	PhaseSaved          = "saved"
+	PhaseFailed         = "failed"
+)
+
+// phases is the state set exported by nexa_job_phase.
+var phases = []string{PhaseLoadingModel, PhaseLoadingDataset, PhaseTraining, PhaseSaved, PhaseFailed}
+
+// JobLogs discovers the trainer's train_<job_id>.log files under a
+// directory and tails each until its job succeeds or fails.
+//
+// The log lines carry no timestamps, so each is dated by when it was read.
+// Lines already in a log when it is discovered are dated by the file's
+// modification time, so a job is only timed when its log is discovered
+// with at most its first line; earlier jobs still count towards the phase
+// and error metrics.
+type JobLogs struct {
+	// Retention is how long the gauges of a finished job are kept.
+	Retention time.Duration
+
+	root    string
+	metrics *Metrics
+	// active holds the logs being tailed by path.
+	active map[string]*jobLog
+	// finished holds the jobs whose logs are complete, by path, until
+	// their gauges are dropped.
+	finished map[string]*jobLog
+	// retired holds the paths of jobs already dropped, so they are not
+	// discovered again.
+	retired map[string]bool
+}
+
+// jobLog is the progress of one job through its log.
+type jobLog struct {
+	id   string
+	tail *Tailer
+	// polled is set after the first poll, which reads the lines written
+	// before discovery.
+	polled bool
+	// timed is cleared when the start of the job predates discovery.
+	timed      bool
+	phase      string
+	phaseStart time.Time
+	first      time.Time
+	done       time.Time
+}
+
+// NewJobLogs returns a tailer for the job logs under root.
+func NewJobLogs(root string, m *Metrics) *JobLogs {
+	return &JobLogs{
+		Retention: DefaultJobRetention,
+		root:      root,
+		metrics:   m,
+		active:    map[string]*jobLog{},
+		finished:  map[string]*jobLog{},
+		retired:   map[string]bool{},
+	}
+}
+
+// Poll looks for new job logs, reads the lines appended to active ones and
+// drops the gauges of jobs that finished longer ago than the retention.
+// Logs of finished jobs untouched for longer than the retention when
+// discovered are ignored.
+func (j *JobLogs) Poll(now time.Time) error {
+	paths, err := filepath.Glob(filepath.Join(j.root, "train_*.log"))
+	if err != nil {
+		return err
+	}
+	present := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		present[path] = true
+		if j.active[path] != nil || j.finished[path] != nil || j.retired[path] {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "train_"), ".log")
+		j.active[path] = &jobLog{id: id, tail: NewTailer(path), timed: true}
+	}
+
+	var errs []error
+	for path, l := range j.active {
+		if !present[path] {
+			// Deleted along with the job's output.
+			l.tail.Close()
+			j.drop(path, l)
+			continue
+		}
+		if err := j.poll(l, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !l.done.IsZero() {
+			l.tail.Close()
+			delete(j.active, path)
+			j.finished[path] = l
+		}
+	}
+	for path, l := range j.finished {
+		if now.Sub(l.done) > j.Retention || !present[path] {
+			j.drop(path, l)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// poll reads the new lines of one job log. The first poll reads what was
+// written before discovery, dated by the modification time: that is exact
+// for a lone first line, so only then is the job timed.
+func (j *JobLogs) poll(l *jobLog, now time.Time) error {
+	if l.polled {
+		_, err := l.tail.Poll(func(line string) { j.apply(l, line, now) })
+		return err
+	}
+	info, err := os.Stat(l.tail.Path())
+	if err != nil {
+		return err
+	}
+	var lines []string
+	_, err = l.tail.Poll(func(line string) { lines = append(lines, line) })
+	l.polled = true
+	if len(lines) == 0 {
+		return err
+	}
+	at := info.ModTime()
+	if now.Sub(at) > j.Retention && finished(lines[len(lines)-1]) {
+		// Finished longer ago than the retention: history, not a job to
+		// report on. A job without its final line may still be training
+		// quietly, so it is reported either way.
+		l.done = at
+		return err
+	}
+	l.timed = len(lines) == 1
+	for _, line := range lines {
+		j.apply(l, line, at)
+	}
+	return err
+}
+
+// apply advances the job's phase for one log line read at time at.
+func (j *JobLogs) apply(l *jobLog, line string, at time.Time) {
+	if l.first.IsZero() {
+		l.first = at
+	}
+	switch {
+	case strings.HasPrefix(line, "[INFO] Loading model"):
+		j.enter(l, PhaseLoadingModel, at)
+	case strings.HasPrefix(line, "[INFO] Loading dataset"):
+		j.enter(l, PhaseLoadingDataset, at)
+	case strings.HasPrefix(line, "[INFO] Starting training"):
+		j.enter(l, PhaseTraining, at)
+	case strings.HasPrefix(line, "[SUCCESS]"):
+		j.enter(l, PhaseSaved, at)
+		j.finish(l, at)
+	case strings.HasPrefix(line, "[ERROR]"):
+		j.metrics.JobErrors.WithLabelValues(phaseOrNone(l.phase), errorCategory(line)).Inc()
+		j.enter(l, PhaseFailed, at)
+		j.finish(l, at)
+	}
+}
+
+// enter moves the job into phase, recording how long the previous phase
+// took when its start was seen.
+func (j *JobLogs) enter(l *jobLog, phase string, at time.Time) {
+	if l.done.IsZero() && l.phase != "" && l.timed {
+		j.metrics.JobPhaseSeconds.WithLabelValues(l.id, l.phase).Set(at.Sub(l.phaseStart).Seconds())
+	}
+	l.phase, l.phaseStart = phase, at
+	for _, p := range phases {
+		v := 0.0
+		if p == phase {
+			v = 1
+		}
+		j.metrics.JobPhase.WithLabelValues(l.id, p).Set(v)
+	}
+}
+
+// finish records the job's duration from its first to its last line.
+func (j *JobLogs) finish(l *jobLog, at time.Time) {
+	if l.done.IsZero() && l.timed {
+		j.metrics.JobDuration.WithLabelValues(l.id).Set(at.Sub(l.first).Seconds())
+	}
+	l.done = at
+}
+
+// drop forgets a job and deletes its gauges.
+func (j *JobLogs) drop(path string, l *jobLog) {
+	delete(j.active, path)
+	delete(j.finished, path)
+	j.retired[path] = true
+	labels := prometheus.Labels{"job_id": l.id}
+	j.metrics.JobPhase.DeletePartialMatch(labels)
+	j.metrics.JobPhaseSeconds.DeletePartialMatch(labels)
+	j.metrics.JobDuration.DeletePartialMatch(labels)
+}
+
+// Close releases the open job logs.
+func (j *JobLogs) Close() error {
+	for _, l := range j.active {
+		l.tail.Close()
+	}
+	return nil
+}
+
+// finished reports whether line ends a job log.
+func finished(line string) bool {
+	return strings.HasPrefix(line, "[SUCCESS]") || strings.HasPrefix(line, "[ERROR]")
+}
+
+func phaseOrNone(phase string) string {
+	if phase == "" {
+		return "none"
+	}
+	return phase
+}
+
+// errorCategories map substrings of trainer errors, matched
+// case-insensitively, to the "category" label. The first match wins.
+var errorCategories = []struct {
+	category string
+	patterns []string
+}{
+	{"auth", []string{"hugging face token", "access token", "invalid token", "401 client error", "403 client error", "unauthorized", "gated repo"}},
+	{"out_of_memory", []string{"out of memory", "outofmemoryerror"}},
+	{"not_found", []string{"404 client error", "not found", "does not exist", "no such file", "not a valid model identifier"}},
+	{"network", []string{"connection", "timed out", "timeout", "max retries", "name resolution"}},
+	{"cuda", []string{"cuda", "nccl", "device-side"}},
+}
+
+// errorCategory classifies an [ERROR] line; unrecognized errors are
+// "other".
+func errorCategory(line string) string {
+	msg := strings.ToLower(strings.TrimPrefix(line, "[ERROR]"))
+	for _, c := range errorCategories {
+		for _, p := range c.patterns {
+			if strings.Contains(msg, p) {
+				return c.category
+			}
+		}
+	}
+	return "other"
+}
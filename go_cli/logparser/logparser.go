@@ -0,0 +1,280 @@
+// Package logparser turns the events in Tune.log and the trainer's per-job
+// logs into Prometheus metrics. Logs are tailed incrementally, so each
+// event is counted exactly once however often it is polled, including
+// across truncation and rotation.
+package logparser
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+// DefaultSessionTimeout is how long a session may stay open before it is
+// counted as abandoned, e.g. because the TUI was killed.
+const DefaultSessionTimeout = 8 * time.Hour
+
+// DefaultOutputRoot is where the trainer writes job output directories,
+// relative to the directory it was started in.
+const DefaultOutputRoot = "nexa_output"
+
+// Parser tails one log file and updates its metrics.
+type Parser struct {
+	// SessionTimeout is how long a session may stay open before it is
+	// counted as abandoned.
+	SessionTimeout time.Duration
+	// OutputRoot is searched for the trainer state of active jobs.
+	OutputRoot string
+	// HealthFromLog feeds the probe metrics and backend_available from the
+	// health checks in the log. Turn it off when the caller probes the
+	// backends itself, so probes are not counted twice.
+	HealthFromLog bool
+
+	tail    *Tailer
+	metrics *Metrics
+	// open holds the start time of every open session by session ID.
+	open map[string]time.Time
+	// jobs holds the jobs submitted but not yet done, by job ID.
+	jobs map[string]job
+	// tokenExpiry is when the session server's token expires; zero when
+	// no token has been stored since the log began.
+	tokenExpiry time.Time
+}
+
+// job is a submitted job as announced in the log.
+type job struct {
+	model  string
+	output string
+}
+
+// New returns a parser for the log at path.
+func New(path string, m *Metrics) *Parser {
+	return &Parser{
+		SessionTimeout: DefaultSessionTimeout,
+		OutputRoot:     DefaultOutputRoot,
+		HealthFromLog:  true,
+		tail:           NewTailer(path),
+		metrics:        m,
+		open:           map[string]time.Time{},
+		jobs:           map[string]job{},
+	}
+}
+
+// Poll reads the events appended since the last poll, expires sessions
+// older than the timeout, refreshes the time-dependent gauges and returns
+// how many lines were read.
+func (p *Parser) Poll() (int, error) {
+	n, err := p.tail.Poll(func(line string) {
+		if strings.TrimSpace(line) != "" {
+			p.Apply(eventlog.Parse(line))
+		}
+	})
+	now := time.Now()
+	p.Expire(now)
+	p.updateTokenExpiry(now)
+	p.updateJobProgress()
+	return n, err
+}
+
+// Path returns the log file being parsed.
+func (p *Parser) Path() string {
+	return p.tail.Path()
+}
+
+// Close releases the log file.
+func (p *Parser) Close() error {
+	return p.tail.Close()
+}
+
+// Apply updates the metrics for one event.
+func (p *Parser) Apply(e eventlog.Event) {
+	switch kind(e) {
+	case eventlog.TypeSessionStart:
+		p.metrics.Sessions.Inc()
+		p.startSession(e)
+	case eventlog.TypeSessionExit:
+		p.exitSession(e)
+	case eventlog.TypeHealthCheck:
+		if !p.HealthFromLog {
+			return
+		}
+		if up, ok := available(e); ok {
+			if up {
+				p.metrics.BackendAvailable.Set(1)
+			} else {
+				p.metrics.BackendAvailable.Set(0)
+			}
+		}
+		if name, h, ok := probe(e); ok {
+			p.metrics.ObserveProbe(name, h)
+		}
+	case eventlog.TypeJobSubmitted:
+		p.submitJob(e)
+	case eventlog.TypeJobStatus:
+		p.finishJob(e)
+	case eventlog.TypeToken:
+		if s, ok := e.Fields["expires_in_s"].(float64); ok && !e.Time.IsZero() {
+			p.tokenExpiry = e.Time.Add(time.Duration(s) * time.Second)
+		}
+	}
+}
+
+func (p *Parser) submitJob(e eventlog.Event) {
+	id, _ := e.Fields["job_id"].(string)
+	model, _ := e.Fields["model"].(string)
+	output, _ := e.Fields["output"].(string)
+	p.metrics.JobsSubmitted.WithLabelValues(model).Inc()
+	if id != "" {
+		p.jobs[id] = job{model: model, output: output}
+	}
+}
+
+// finishJob counts a job reaching a terminal status and drops its
+// progress gauges. The model comes from the submission when the status
+// event does not carry it.
+func (p *Parser) finishJob(e eventlog.Event) {
+	id, _ := e.Fields["job_id"].(string)
+	status, _ := e.Fields["status"].(string)
+	j, known := p.jobs[id]
+	if model, ok := e.Fields["model"].(string); ok {
+		j.model = model
+	}
+	switch status {
+	case "finished":
+		p.metrics.JobsFinished.WithLabelValues(j.model).Inc()
+	case "error", "lost":
+		p.metrics.JobsFailed.WithLabelValues(j.model, status).Inc()
+	default:
+		return
+	}
+	if known {
+		delete(p.jobs, id)
+		p.metrics.JobLoss.DeleteLabelValues(id, j.model)
+		p.metrics.JobStep.DeleteLabelValues(id, j.model)
+	}
+}
+
+// updateJobProgress reads the latest trainer state of every active job.
+// Jobs that have not checkpointed yet have no gauges.
+func (p *Parser) updateJobProgress() {
+	for id, j := range p.jobs {
+		if j.output == "" {
+			continue
+		}
+		state, err := trainerstate.Find(filepath.Join(p.OutputRoot, j.output))
+		if err != nil {
+			continue
+		}
+		p.metrics.JobStep.WithLabelValues(id, j.model).Set(float64(state.GlobalStep))
+		if loss, ok := state.FinalLoss(); ok {
+			p.metrics.JobLoss.WithLabelValues(id, j.model).Set(loss)
+		}
+	}
+}
+
+func (p *Parser) updateTokenExpiry(now time.Time) {
+	if !p.tokenExpiry.IsZero() {
+		p.metrics.SetTokenExpiry(p.tokenExpiry.Sub(now))
+	}
+}
+
+// startSession opens the session of e. A process runs one fine-tune
+// session at a time, so a session it left open is abandoned. Starts
+// without a timestamp cannot be timed and are only counted.
+func (p *Parser) startSession(e eventlog.Event) {
+	if e.Time.IsZero() {
+		return
+	}
+	if _, ok := p.open[e.Session]; ok {
+		p.metrics.SessionsAbandoned.Inc()
+	}
+	p.open[e.Session] = e.Time
+	p.metrics.SessionsActive.Set(float64(len(p.open)))
+}
+
+// exitSession closes the session of e and records its duration. Exits
+// without a matching start, e.g. one that was rotated away before the
+// parser saw it or that was already abandoned, are ignored.
+func (p *Parser) exitSession(e eventlog.Event) {
+	start, ok := p.open[e.Session]
+	if !ok || e.Time.IsZero() {
+		return
+	}
+	delete(p.open, e.Session)
+	p.metrics.SessionsActive.Set(float64(len(p.open)))
+	if d := e.Time.Sub(start); d >= 0 {
+		p.metrics.SessionDuration.Observe(d.Seconds())
+	}
+}
+
+// Expire abandons sessions that have been open longer than the session
+// timeout at now.
+func (p *Parser) Expire(now time.Time) {
+	for id, start := range p.open {
+		if now.Sub(start) > p.SessionTimeout {
+			delete(p.open, id)
+			p.metrics.SessionsAbandoned.Inc()
+		}
+	}
+	p.metrics.SessionsActive.Set(float64(len(p.open)))
+}
+
+// kind returns the event type, recognizing the free-text messages of the
+// legacy log format. Legacy events carry no session ID, so their sessions
+// pair up in order as if written by a single process.
+func kind(e eventlog.Event) string {
+	if e.Type != eventlog.TypeLegacy {
+		return e.Type
+	}
+	switch {
+	case strings.HasPrefix(e.Message, "Started fine-tune session"):
+		return eventlog.TypeSessionStart
+	case strings.HasPrefix(e.Message, "Exited fine-tune session"):
+		return eventlog.TypeSessionExit
+	case strings.HasPrefix(e.Message, "Backend health checked:"):
+		return eventlog.TypeHealthCheck
+	}
+	return eventlog.TypeLegacy
+}
+
+// available reports the outcome of a health check event. Events that only
+// announce a probe, such as "Pinging backend...", carry no outcome.
+func available(e eventlog.Event) (up, ok bool) {
+	if e.Type == eventlog.TypeLegacy {
+		return !strings.Contains(e.Message, "Backend not available") && strings.Contains(e.Message, `"status":"ok"`), true
+	}
+	status, ok := e.Fields["status"].(string)
+	if !ok {
+		return false, false
+	}
+	return status == "ok", true
+}
+
+// probe rebuilds the health probe behind a structured health check event.
+func probe(e eventlog.Event) (string, backend.Health, bool) {
+	var h backend.Health
+	name, _ := e.Fields["backend"].(string)
+	h.Endpoint, _ = e.Fields["endpoint"].(string)
+	h.Status, _ = e.Fields["status"].(string)
+	if name == "" || h.Endpoint == "" || h.Status == "" {
+		return "", h, false
+	}
+	if ms, ok := e.Fields["latency_ms"].(float64); ok {
+		h.Latency = time.Duration(ms * float64(time.Millisecond))
+	}
+	if msg, ok := e.Fields["error"].(string); ok {
+		h.Err = errors.New(msg)
+	}
+	if components, ok := e.Fields["components"].(map[string]any); ok {
+		h.Components = make(map[string]string, len(components))
+		for k, v := range components {
+			h.Components[k], _ = v.(string)
+		}
+	}
+	return name, h, true
+}
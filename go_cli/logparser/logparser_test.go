@@ -0,0 +1,594 @@
+package logparser
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+)
+
+// Counts in testdata/Tune.log, a log written by an early TUI version.
+const (
+	fixtureSessions  = 33
+	fixtureExits     = 30
+	fixtureAbandoned = 3
+)
+
+func fixtureLines(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "Tune.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// logFile is a log being written by a test, fed from the fixture.
+type logFile struct {
+	t     *testing.T
+	path  string
+	lines []string
+}
+
+// write appends the next n fixture lines, or all remaining ones if n < 0.
+func (l *logFile) write(n int) {
+	l.t.Helper()
+	if n < 0 || n > len(l.lines) {
+		n = len(l.lines)
+	}
+	l.append(strings.Join(l.lines[:n], ""))
+	l.lines = l.lines[n:]
+}
+
+func (l *logFile) append(s string) {
+	l.t.Helper()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s); err != nil {
+		l.t.Fatal(err)
+	}
+}
+
+func TestParserCountsEachEventOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(l *logFile, poll func())
+		// skip is the range of fixture lines the tailer cannot see, as
+		// they were rotated away before it ever opened their file.
+		skip [2]int
+	}{
+		{name: "single pass", run: func(l *logFile, poll func()) {
+			l.write(-1)
+			poll()
+		}},
+		{name: "repeated polls", run: func(l *logFile, poll func()) {
+			l.write(-1)
+			for i := 0; i < 5; i++ {
+				poll()
+			}
+		}},
+		{name: "small appends", run: func(l *logFile, poll func()) {
+			for len(l.lines) > 0 {
+				l.write(7)
+				poll()
+			}
+		}},
+		{name: "partial lines", run: func(l *logFile, poll func()) {
+			for len(l.lines) > 0 {
+				line := l.lines[0]
+				l.lines = l.lines[1:]
+				l.append(line[:len(line)/2])
+				poll()
+				l.append(line[len(line)/2:])
+				poll()
+			}
+		}},
+		{name: "file appears later", run: func(l *logFile, poll func()) {
+			poll()
+			l.write(-1)
+			poll()
+		}},
+		{name: "truncated", run: func(l *logFile, poll func()) {
+			l.write(40)
+			poll()
+			if err := os.Truncate(l.path, 0); err != nil {
+				t.Fatal(err)
+			}
+			poll()
+			l.write(-1)
+			poll()
+		}},
+		{name: "rotated with unread lines", run: func(l *logFile, poll func()) {
+			l.write(30)
+			poll()
+			l.write(20)
+			if err := os.Rename(l.path, l.path+".1"); err != nil {
+				t.Fatal(err)
+			}
+			l.write(-1)
+			poll()
+		}},
+		{name: "rotated, compressed and pruned", run: func(l *logFile, poll func()) {
+			l.write(30)
+			poll()
+			l.write(20)
+			if _, err := eventlog.Rotate(l.path, eventlog.RotateConfig{Compress: true}); err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range eventlog.Archives(l.path) {
+				os.Remove(a)
+			}
+			poll()
+			l.write(-1)
+			poll()
+		}},
+		{name: "rotated twice between polls", run: func(l *logFile, poll func()) {
+			l.write(30)
+			poll()
+			os.Rename(l.path, l.path+".1")
+			l.write(30)
+			os.Rename(l.path, l.path+".2")
+			l.write(-1)
+			poll()
+			poll()
+		}, skip: [2]int{30, 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &logFile{t: t, path: filepath.Join(t.TempDir(), "Tune.log"), lines: fixtureLines(t)}
+			m := NewMetrics(prometheus.NewRegistry())
+			p := New(l.path, m)
+			defer p.Close()
+			tt.run(l, func() {
+				t.Helper()
+				if _, err := p.Poll(); err != nil {
+					t.Fatal(err)
+				}
+			})
+			lines := fixtureLines(t)
+			want := countSessions(lines) - countSessions(lines[tt.skip[0]:tt.skip[1]])
+			if got := testutil.ToFloat64(m.Sessions); got != want {
+				t.Errorf("tune_session_total = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func countSessions(lines []string) float64 {
+	n := 0
+	for _, l := range lines {
+		if strings.Contains(l, "] Started fine-tune session") {
+			n++
+		}
+	}
+	return float64(n)
+}
+
+func TestParserMetricsFromFixture(t *testing.T) {
+	path := filepath.Join("testdata", "Tune.log")
+	m := NewMetrics(prometheus.NewRegistry())
+	p := New(path, m)
+	defer p.Close()
+	n, err := p.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(fixtureLines(t)); n != want {
+		t.Errorf("Poll read %d lines, want %d", n, want)
+	}
+	if got := testutil.ToFloat64(m.Sessions); got != fixtureSessions {
+		t.Errorf("tune_session_total = %v, want %d", got, fixtureSessions)
+	}
+	if got := testutil.ToFloat64(m.BackendAvailable); got != 1 {
+		t.Errorf("backend_available = %v, want 1 after the last healthy check", got)
+	}
+	var pb dto.Metric
+	if err := m.SessionDuration.Write(&pb); err != nil {
+		t.Fatal(err)
+	}
+	if got := pb.GetHistogram().GetSampleCount(); got != fixtureExits {
+		t.Errorf("observed %d session durations, want %d", got, fixtureExits)
+	}
+	if got := testutil.ToFloat64(m.SessionsAbandoned); got != fixtureAbandoned {
+		t.Errorf("tune_session_abandoned_total = %v, want %d", got, fixtureAbandoned)
+	}
+	if got := testutil.ToFloat64(m.SessionsActive); got != 0 {
+		t.Errorf("tune_session_active = %v, want 0 once old sessions expire", got)
+	}
+	if n, _ := p.Poll(); n != 0 {
+		t.Errorf("second Poll read %d lines, want 0", n)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		sessions float64
+		up       float64
+	}{
+		{"legacy unavailable", []string{
+			`[2025-06-30 12:02:46] Started fine-tune session`,
+			`[2025-06-30 12:02:46] Backend health checked: Backend not available: Get "http://localhost:8000/health": connection refused`,
+		}, 1, 0},
+		{"legacy ok", []string{
+			`[2025-06-30 14:42:21] Backend health checked: {"status":"ok","components":{"session_server":"ok","trainer":"ok"}}`,
+		}, 0, 1},
+		{"structured", []string{
+			`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"session.start","session":"a1","msg":"Started fine-tune session, pinging backend..."}`,
+			`{"ts":"2025-07-01T10:00:01Z","level":"warn","event":"health.check","session":"a1","msg":"Backend health checked","fields":{"status":"error"}}`,
+			`{"ts":"2025-07-01T10:00:02Z","level":"info","event":"health.check","session":"a1","msg":"Backend health checked","fields":{"status":"ok"}}`,
+			`{"ts":"2025-07-01T10:00:03Z","level":"debug","event":"health.check","session":"a1","msg":"Pinging backend..."}`,
+		}, 1, 1},
+		{"untimestamped lines", []string{
+			`Launching CLI mode (cli.py)`,
+			`Backend health checked: Backend not available: dial tcp [::1]:8765: connection refused`,
+		}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry())
+			m.BackendAvailable.Set(-1)
+			p := New("", m)
+			for _, line := range tt.lines {
+				p.Apply(eventlog.Parse(line))
+			}
+			if got := testutil.ToFloat64(m.Sessions); got != tt.sessions {
+				t.Errorf("tune_session_total = %v, want %v", got, tt.sessions)
+			}
+			if got := testutil.ToFloat64(m.BackendAvailable); got != tt.up {
+				t.Errorf("backend_available = %v, want %v", got, tt.up)
+			}
+		})
+	}
+}
+
+func TestSessionPairing(t *testing.T) {
+	t0 := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	ev := func(typ, session string, offset time.Duration) eventlog.Event {
+		return eventlog.Event{Time: t0.Add(offset), Type: typ, Session: session}
+	}
+	start, exit := eventlog.TypeSessionStart, eventlog.TypeSessionExit
+	tests := []struct {
+		name      string
+		events    []eventlog.Event
+		now       time.Duration
+		durations []float64
+		abandoned float64
+		active    float64
+	}{
+		{"one session", []eventlog.Event{
+			ev(start, "a", 0), ev(exit, "a", 90*time.Second),
+		}, time.Hour, []float64{90}, 0, 0},
+		{"overlapping processes", []eventlog.Event{
+			ev(start, "a", 0),
+			ev(start, "b", 10*time.Second),
+			ev(exit, "a", 40*time.Second),
+			ev(start, "a", 50*time.Second),
+			ev(exit, "b", 70*time.Second),
+			ev(exit, "a", 80*time.Second),
+		}, time.Hour, []float64{40, 60, 30}, 0, 0},
+		{"restart without exit", []eventlog.Event{
+			ev(start, "a", 0), ev(start, "a", time.Minute), ev(exit, "a", 2*time.Minute),
+		}, time.Hour, []float64{60}, 1, 0},
+		{"still open", []eventlog.Event{
+			ev(start, "a", 0),
+		}, time.Hour, nil, 0, 1},
+		{"past the timeout", []eventlog.Event{
+			ev(start, "a", 0), ev(start, "b", 0), ev(exit, "b", time.Minute),
+		}, DefaultSessionTimeout + time.Second, []float64{60}, 1, 0},
+		{"exit without start", []eventlog.Event{
+			ev(exit, "a", 0),
+		}, time.Hour, nil, 0, 0},
+		{"untimestamped start", []eventlog.Event{
+			{Type: start, Session: "a"}, ev(exit, "a", time.Minute),
+		}, time.Hour, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry())
+			p := New("", m)
+			for _, e := range tt.events {
+				p.Apply(e)
+			}
+			p.Expire(t0.Add(tt.now))
+
+			var pb dto.Metric
+			if err := m.SessionDuration.Write(&pb); err != nil {
+				t.Fatal(err)
+			}
+			h := pb.GetHistogram()
+			var sum float64
+			for _, d := range tt.durations {
+				sum += d
+			}
+			if h.GetSampleCount() != uint64(len(tt.durations)) || h.GetSampleSum() != sum {
+				t.Errorf("durations: %d samples summing to %v, want %v", h.GetSampleCount(), h.GetSampleSum(), tt.durations)
+			}
+			if got := testutil.ToFloat64(m.SessionsAbandoned); got != tt.abandoned {
+				t.Errorf("tune_session_abandoned_total = %v, want %v", got, tt.abandoned)
+			}
+			if got := testutil.ToFloat64(m.SessionsActive); got != tt.active {
+				t.Errorf("tune_session_active = %v, want %v", got, tt.active)
+			}
+		})
+	}
+}
+
+func TestJobMetrics(t *testing.T) {
+	root := t.TempDir()
+	state := `{"global_step": 120, "max_steps": 300, "log_history": [{"step": 100, "loss": 1.25}, {"step": 120, "loss": 0.875}]}`
+	if err := os.MkdirAll(filepath.Join(root, "run-a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "run-a", "trainer_state.json"), []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMetrics(prometheus.NewRegistry())
+	p := New("", m)
+	p.OutputRoot = root
+	for _, line := range []string{
+		`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"job.submitted","msg":"Training job submitted","fields":{"job_id":"a","model":"gpt2","output":"run-a"}}`,
+		`{"ts":"2025-07-01T10:00:01Z","level":"info","event":"job.submitted","msg":"Training job submitted","fields":{"job_id":"b","model":"gpt2","output":"run-b"}}`,
+		`{"ts":"2025-07-01T10:00:02Z","level":"info","event":"job.submitted","msg":"Training job submitted","fields":{"job_id":"c","model":"olmo","output":"run-c"}}`,
+		`{"ts":"2025-07-01T10:10:00Z","level":"error","event":"job.status","msg":"Job error","fields":{"job_id":"b","status":"error"}}`,
+		`{"ts":"2025-07-01T10:20:00Z","level":"info","event":"job.status","msg":"Job finished","fields":{"job_id":"c","model":"olmo","status":"finished"}}`,
+	} {
+		p.Apply(eventlog.Parse(line))
+	}
+	p.updateJobProgress()
+
+	tests := []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"submitted gpt2", m.JobsSubmitted.WithLabelValues("gpt2"), 2},
+		{"submitted olmo", m.JobsSubmitted.WithLabelValues("olmo"), 1},
+		{"failed gpt2", m.JobsFailed.WithLabelValues("gpt2", "error"), 1},
+		{"finished olmo", m.JobsFinished.WithLabelValues("olmo"), 1},
+		{"finished gpt2", m.JobsFinished.WithLabelValues("gpt2"), 0},
+		{"step of active job", m.JobStep.WithLabelValues("a", "gpt2"), 120},
+		{"loss of active job", m.JobLoss.WithLabelValues("a", "gpt2"), 0.875},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(tt.c); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	// Only job a has progress: b and c are done and b never checkpointed.
+	if n := testutil.CollectAndCount(m.JobStep); n != 1 {
+		t.Errorf("job step series = %d, want 1", n)
+	}
+}
+
+func TestProbeMetrics(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	p := New("", m)
+	for _, line := range []string{
+		`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"health.check","msg":"Backend health checked","fields":{"backend":"trainer","endpoint":"http://localhost:8770","status":"ok","latency_ms":12.5,"components":{"session_server":"ok","trainer":"ok"}}}`,
+		`{"ts":"2025-07-01T10:00:05Z","level":"warn","event":"health.check","msg":"Backend health checked","fields":{"backend":"trainer","endpoint":"http://localhost:8770","status":"degraded","latency_ms":40,"components":{"session_server":"down","trainer":"ok"}}}`,
+		`{"ts":"2025-07-01T10:00:10Z","level":"warn","event":"health.check","msg":"Backend health checked","fields":{"backend":"session","endpoint":"unix:///run/nexa/session.sock","status":"unavailable","latency_ms":0.2,"error":"connection refused"}}`,
+		`{"ts":"2025-07-01T10:00:11Z","level":"debug","event":"health.check","msg":"Pinging backend..."}`,
+	} {
+		p.Apply(eventlog.Parse(line))
+	}
+	tests := []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"trainer successes", m.Probes.WithLabelValues("trainer", "http://localhost:8770", "success"), 1},
+		{"trainer failures", m.Probes.WithLabelValues("trainer", "http://localhost:8770", "failure"), 1},
+		{"session failures", m.Probes.WithLabelValues("session", "unix:///run/nexa/session.sock", "failure"), 1},
+		{"session_server component down", m.ComponentChecks.WithLabelValues("trainer", "http://localhost:8770", "session_server", "failure"), 1},
+		{"trainer component up", m.ComponentChecks.WithLabelValues("trainer", "http://localhost:8770", "trainer", "success"), 2},
+		{"backend_available", m.BackendAvailable, 0},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(tt.c); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	var pb dto.Metric
+	if err := m.ProbeDuration.WithLabelValues("trainer", "http://localhost:8770").(prometheus.Metric).Write(&pb); err != nil {
+		t.Fatal(err)
+	}
+	if h := pb.GetHistogram(); h.GetSampleCount() != 2 || math.Abs(h.GetSampleSum()-0.0525) > 1e-9 {
+		t.Errorf("trainer probe latency: %d samples summing to %v, want 2 summing to 0.0525", h.GetSampleCount(), h.GetSampleSum())
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	t0 := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		lines []string
+		now   time.Duration
+		want  float64
+	}{
+		{"fresh token", []string{
+			`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"token","msg":"Token stored in session server","fields":{"profile":"work","expires_in_s":1800}}`,
+		}, 10 * time.Minute, 1200},
+		{"expired", []string{
+			`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"token","msg":"Token stored in session server","fields":{"expires_in_s":1800}}`,
+		}, time.Hour, 0},
+		{"cleared", []string{
+			`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"token","msg":"Token stored in session server","fields":{"expires_in_s":1800}}`,
+			`{"ts":"2025-07-01T10:05:00Z","level":"info","event":"token","msg":"Vault locked","fields":{"expires_in_s":0}}`,
+		}, 10 * time.Minute, 0},
+		{"unrelated token events", []string{
+			`{"ts":"2025-07-01T10:00:00Z","level":"info","event":"token","msg":"Token profile added","fields":{"profile":"work"}}`,
+		}, time.Minute, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry())
+			m.TokenExpiry.Set(-1)
+			p := New("", m)
+			for _, line := range tt.lines {
+				p.Apply(eventlog.Parse(line))
+			}
+			p.updateTokenExpiry(t0.Add(tt.now))
+			if got := testutil.ToFloat64(m.TokenExpiry); got != tt.want {
+				t.Errorf("nexa_session_token_expiry_seconds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobLogs(t *testing.T) {
+	root := t.TempDir()
+	t0 := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	write := func(id, text string, mtime time.Time) {
+		t.Helper()
+		path := filepath.Join(root, "train_"+id+".log")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		if !mtime.IsZero() {
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	m := NewMetrics(prometheus.NewRegistry())
+	j := NewJobLogs(root, m)
+	poll := func(at time.Time) {
+		t.Helper()
+		if err := j.Poll(at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Finished days before the monitor looked: ignored.
+	write("old", "[INFO] Loading model and tokenizer: gpt2\n[ERROR] CUDA out of memory\n", t0.Add(-72*time.Hour))
+	// Found with two lines already written: counted but not timed.
+	write("b", "[INFO] Loading model and tokenizer: gpt2\n[INFO] Loading dataset: imdb\n", t0)
+	// Found with its only line: dated exactly by its modification time.
+	write("c", "[ERROR] No Hugging Face token found in session. Aborting.\n", t0)
+	// Followed from an empty file.
+	write("a", "", t0)
+	poll(t0)
+
+	write("a", "[INFO] Loading model and tokenizer: gpt2\n", time.Time{})
+	poll(t0.Add(10 * time.Second))
+	write("a", "[INFO] Loading dataset: imdb\n", time.Time{})
+	write("b", "[ERROR] CUDA out of memory. Tried to allocate 2.00 GiB\n", time.Time{})
+	poll(t0.Add(70 * time.Second))
+	write("a", "[INFO] Starting training...\n", time.Time{})
+	poll(t0.Add(100 * time.Second))
+	write("a", "[SUCCESS] Model and tokenizer saved to /out/run-a!\n", time.Time{})
+	poll(t0.Add(400 * time.Second))
+	// Lines after the job ended are not read.
+	write("a", "[ERROR] late\n", time.Time{})
+	poll(t0.Add(410 * time.Second))
+
+	// Job b started before it was found, so it has no duration.
+	if n := testutil.CollectAndCount(m.JobDuration); n != 2 {
+		t.Errorf("duration series = %d, want a and c", n)
+	}
+	if n := testutil.CollectAndCount(m.JobErrors); n != 2 {
+		t.Errorf("error series = %d, want 2", n)
+	}
+
+	tests := []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"a loading model", m.JobPhaseSeconds.WithLabelValues("a", PhaseLoadingModel), 60},
+		{"a loading dataset", m.JobPhaseSeconds.WithLabelValues("a", PhaseLoadingDataset), 30},
+		{"a training", m.JobPhaseSeconds.WithLabelValues("a", PhaseTraining), 300},
+		{"a duration", m.JobDuration.WithLabelValues("a"), 390},
+		{"a saved", m.JobPhase.WithLabelValues("a", PhaseSaved), 1},
+		{"a training now", m.JobPhase.WithLabelValues("a", PhaseTraining), 0},
+		{"b failed", m.JobPhase.WithLabelValues("b", PhaseFailed), 1},
+		{"c duration", m.JobDuration.WithLabelValues("c"), 0},
+		{"oom while loading dataset", m.JobErrors.WithLabelValues(PhaseLoadingDataset, "out_of_memory"), 1},
+		{"missing token", m.JobErrors.WithLabelValues("none", "auth"), 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(tt.c); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	poll(t0.Add(400*time.Second + DefaultJobRetention + time.Minute))
+	if n := testutil.CollectAndCount(m.JobPhase); n != 0 {
+		t.Errorf("phase series after retention = %d, want 0", n)
+	}
+}
+
+func TestJobLogsLongRun(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2025, 7, 3, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(root, "train_long.log")
+	text := "[INFO] Loading model and tokenizer: gpt2\n[INFO] Loading dataset: imdb\n[INFO] Starting training...\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Training has printed nothing for longer than the retention when the
+	// monitor restarts; it is still running.
+	quiet := now.Add(-DefaultJobRetention - 6*time.Hour)
+	if err := os.Chtimes(path, quiet, quiet); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMetrics(prometheus.NewRegistry())
+	j := NewJobLogs(root, m)
+	defer j.Close()
+	if err := j.Poll(now); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(m.JobPhase.WithLabelValues("long", PhaseTraining)); got != 1 {
+		t.Fatalf("training phase = %v, want 1", got)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("[SUCCESS] Model and tokenizer saved to /out/long!\n")
+	f.Close()
+	if err := j.Poll(now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(m.JobPhase.WithLabelValues("long", PhaseSaved)); got != 1 {
+		t.Errorf("saved phase = %v, want 1", got)
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"[ERROR] No Hugging Face token found in session. Aborting.", "auth"},
+		{"[ERROR] 401 Client Error: Unauthorized for url: https://huggingface.co/api/models/x", "auth"},
+		{"[ERROR] CUDA out of memory. Tried to allocate 2.00 GiB", "out_of_memory"},
+		{"[ERROR] foo is not a local folder and is not a valid model identifier", "not_found"},
+		{"[ERROR] Can't load tokenizer for 'gpt2'", "other"},
+		{"[ERROR] Connection error, and we cannot find the requested files in the cached path", "network"},
+	}
+	for _, tt := range tests {
+		if got := errorCategory(tt.line); got != tt.want {
+			t.Errorf("errorCategory(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package logparser
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+)
+
+// namespace prefixes metrics added after the original tune_session_* and
+// backend_available ones, whose names are kept for existing dashboards.
+const namespace = "nexa"
+
+// sessionBuckets span fine-tune sessions from a quick look at the backend
+// status, which takes seconds, to a long interactive run of a few hours.
+var sessionBuckets = []float64{5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200, 14400}
+
+// probeBuckets span health probes from a local socket (milliseconds) to the
+// two second probe timeout.
+var probeBuckets = prometheus.ExponentialBuckets(0.005, 2, 10)
+
+// Probe results used as the "result" label.
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// Metrics are the collectors fed by the parser.
+type Metrics struct {
+	Sessions          prometheus.Counter
+	SessionsActive    prometheus.Gauge
+	SessionsAbandoned prometheus.Counter
+	SessionDuration   prometheus.Histogram
+	BackendAvailable  prometheus.Gauge
+
+	JobsSubmitted *prometheus.CounterVec
+	JobsFinished  *prometheus.CounterVec
+	JobsFailed    *prometheus.CounterVec
+	JobLoss       *prometheus.GaugeVec
+	JobStep       *prometheus.GaugeVec
+
+	JobPhase        *prometheus.GaugeVec
+	JobPhaseSeconds *prometheus.GaugeVec
+	JobDuration     *prometheus.GaugeVec
+	JobErrors       *prometheus.CounterVec
+
+	ProbeDuration   *prometheus.HistogramVec
+	Probes          *prometheus.CounterVec
+	ComponentChecks *prometheus.CounterVec
+
+	TokenExpiry prometheus.Gauge
+}
+
+// NewMetrics creates the collectors and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		Sessions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tune_session_total",
+			Help: "Total number of tune sessions started.",
+		}),
+		SessionsActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tune_session_active",
+			Help: "Tune sessions started but not yet exited or abandoned.",
+		}),
+		SessionsAbandoned: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tune_session_abandoned_total",
+			Help: "Tune sessions that ended without an exit event.",
+		}),
+		SessionDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tune_session_duration_seconds",
+			Help:    "Duration of tune sessions from start to exit.",
+			Buckets: sessionBuckets,
+		}),
+		BackendAvailable: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "backend_available",
+			Help: "Whether the last logged backend health check succeeded (1) or not (0).",
+		}),
+
+		JobsSubmitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_submitted_total",
+			Help:      "Fine-tune jobs submitted to the trainer.",
+		}, []string{"model"}),
+		JobsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_finished_total",
+			Help:      "Fine-tune jobs that finished successfully.",
+		}, []string{"model"}),
+		JobsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_failed_total",
+			Help:      "Fine-tune jobs that errored or were lost by the trainer.",
+		}, []string{"model", "status"}),
+		JobLoss: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_training_loss",
+			Help:      "Last logged training loss of each active job.",
+		}, []string{"job_id", "model"}),
+		JobStep: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_training_step",
+			Help:      "Last checkpointed global step of each active job.",
+		}, []string{"job_id", "model"}),
+
+		JobPhase: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_phase",
+			Help:      "Phase of each recent job from its trainer log: 1 for the current phase, 0 for the others.",
+		}, []string{"job_id", "phase"}),
+		JobPhaseSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_phase_duration_seconds",
+			Help:      "Time each recent job spent in each completed phase of its trainer log.",
+		}, []string{"job_id", "phase"}),
+		JobDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_duration_seconds",
+			Help:      "Time from the first to the last line of each recent finished job's trainer log.",
+		}, []string{"job_id"}),
+		JobErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "job_errors_total",
+			Help:      "Errors in trainer job logs by the phase they occurred in and category.",
+		}, []string{"phase", "category"}),
+
+		ProbeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "health_probe_duration_seconds",
+			Help:      "Latency of backend /health probes.",
+			Buckets:   probeBuckets,
+		}, []string{"backend", "endpoint"}),
+		Probes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "health_probes_total",
+			Help:      "Backend /health probes by result.",
+		}, []string{"backend", "endpoint", "result"}),
+		ComponentChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "health_component_checks_total",
+			Help:      "Components reported by backend /health probes, by result.",
+		}, []string{"backend", "endpoint", "component", "result"}),
+
+		TokenExpiry: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "session_token_expiry_seconds",
+			Help:      "Seconds until the token held by the session server expires; 0 when there is none.",
+		}),
+	}
+	reg.MustRegister(
+		m.Sessions, m.SessionsActive, m.SessionsAbandoned, m.SessionDuration, m.BackendAvailable,
+		m.JobsSubmitted, m.JobsFinished, m.JobsFailed, m.JobLoss, m.JobStep,
+		m.JobPhase, m.JobPhaseSeconds, m.JobDuration, m.JobErrors,
+		m.ProbeDuration, m.Probes, m.ComponentChecks,
+		m.TokenExpiry,
+	)
+	return m
+}
+
+// ObserveProbe records one health probe of the named backend ("trainer" or
+// "session").
+func (m *Metrics) ObserveProbe(name string, h backend.Health) {
+	result := resultSuccess
+	if !h.OK() {
+		result = resultFailure
+	}
+	m.Probes.WithLabelValues(name, h.Endpoint, result).Inc()
+	m.ProbeDuration.WithLabelValues(name, h.Endpoint).Observe(h.Latency.Seconds())
+	for component, status := range h.Components {
+		result := resultSuccess
+		if status != "ok" {
+			result = resultFailure
+		}
+		m.ComponentChecks.WithLabelValues(name, h.Endpoint, component, result).Inc()
+	}
+}
+
+// SetTokenExpiry records how long the session server's token remains
+// valid.
+func (m *Metrics) SetTokenExpiry(remaining time.Duration) {
+	m.TokenExpiry.Set(max(remaining, 0).Seconds())
+}
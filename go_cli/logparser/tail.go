@@ -0,0 +1,151 @@
+package logparser
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+// maxLine bounds a single log line; longer lines are skipped.
+const maxLine = 64 << 10
+
+// Tailer reads a log file incrementally. It remembers the byte offset of
+// the first unread line and the inode of the open file, and keeps the file
+// open, so a file that is rotated away is read to the end before the tailer
+// switches to the new file at the same path. A file that shrinks below the
+// offset, as when it is cleared, is read again from the start.
+type Tailer struct {
+	path   string
+	f      *os.File
+	info   os.FileInfo
+	offset int64
+	// skipping is set while the rest of an oversized line is discarded.
+	skipping bool
+}
+
+// NewTailer returns a tailer for path. Nothing is read until Poll.
+func NewTailer(path string) *Tailer {
+	return &Tailer{path: path}
+}
+
+// Path returns the file being tailed.
+func (t *Tailer) Path() string {
+	return t.path
+}
+
+// Offset returns the position of the first unread byte in the current file.
+func (t *Tailer) Offset() int64 {
+	return t.offset
+}
+
+// Poll calls fn for every complete line appended since the last poll and
+// returns how many lines it read. A trailing line without a newline is left
+// for the next poll, as the writer may still be in the middle of it. A
+// missing file is not an error; it is picked up once it appears.
+func (t *Tailer) Poll(fn func(line string)) (int, error) {
+	n := 0
+	if t.f != nil {
+		read, err := t.drain(fn)
+		n += read
+		if err != nil {
+			return n, err
+		}
+	}
+	info, err := os.Stat(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return n, nil
+	}
+	if err != nil {
+		return n, err
+	}
+	if t.f != nil && os.SameFile(info, t.info) {
+		return n, nil
+	}
+	// The path is new or now names a different inode: the old file was
+	// rotated away and has just been read to its end.
+	if err := t.open(); err != nil {
+		return n, err
+	}
+	read, err := t.drain(fn)
+	return n + read, err
+}
+
+func (t *Tailer) open() error {
+	t.Close()
+	f, err := os.Open(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	t.f, t.info, t.offset, t.skipping = f, info, 0, false
+	return nil
+}
+
+// drain reads complete lines from the open file starting at the offset.
+func (t *Tailer) drain(fn func(line string)) (int, error) {
+	info, err := t.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() < t.offset {
+		// Truncated in place: everything in the file is new.
+		t.offset, t.skipping = 0, false
+	}
+	if info.Size() == t.offset {
+		return 0, nil
+	}
+	if _, err := t.f.Seek(t.offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r := bufio.NewReaderSize(t.f, maxLine)
+	n := 0
+	for {
+		line, err := r.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// Discard an oversized line rather than stall on it.
+			t.offset += int64(len(line))
+			t.skipping = true
+			continue
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		t.offset += int64(len(line))
+		if t.skipping {
+			t.skipping = false
+			continue
+		}
+		fn(trimEOL(line))
+		n++
+	}
+}
+
+// Close releases the open file. The next Poll reopens the path and reads
+// it from the start.
+func (t *Tailer) Close() error {
+	if t.f == nil {
+		return nil
+	}
+	err := t.f.Close()
+	t.f, t.info, t.offset, t.skipping = nil, nil, 0, false
+	return err
+}
+
+func trimEOL(line []byte) string {
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return string(line)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+)
+
+const logFollowInterval = 2 * time.Second
+
+var (
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#16161a")).
+			Background(lipgloss.Color("#FFD21F"))
+	levelFilters = []eventlog.Level{"", eventlog.Info, eventlog.Warn, eventlog.Error}
+	timeRanges   = []struct {
+		label string
+		span  time.Duration
+	}{{"all", 0}, {"1h", time.Hour}, {"24h", 24 * time.Hour}, {"7d", 7 * 24 * time.Hour}}
+)
+
+// levelRank orders levels for the minimum-level filter.
+func levelRank(l eventlog.Level) int {
+	switch l {
+	case eventlog.Debug:
+		return 0
+	case eventlog.Warn:
+		return 2
+	case eventlog.Error:
+		return 3
+	}
+	return 1
+}
+
+// --- Log Viewer ---
+// logViewer shows either the local Tune.log or the trainer log of a past
+// job in a scrollable viewport with search and filters.
+type logViewer struct {
+	viewport  viewport.Model
+	events    []eventlog.Event
+	jobMode   bool // showing a trainer job log instead of Tune.log
+	jobs      []history.Job
+	jobIdx    int
+	follow    bool
+	searching bool
+	input     string
+	query     string
+	matches   []int // line numbers of search matches
+	matchIdx  int
+	levelIdx  int
+	typeIdx   int // 0 means all event types
+	types     []string
+	rangeIdx  int
+	status    string
+	followGen int
+}
+
+type logEventsMsg struct {
+	events []eventlog.Event
+	err    error
+}
+
+// logFollowMsg carries the generation of the follow loop that scheduled it,
+// so that stale loops stop once a newer one has started.
+type logFollowMsg struct{ gen int }
+
+func newLogViewer(width, height int) logViewer {
+	return logViewer{viewport: viewport.New(width, height), follow: true}
+}
+
+// open refreshes the job list and starts loading the current source.
+func (lv *logViewer) open() tea.Cmd {
+	lv.jobs = loadHistory()
+	if lv.jobIdx >= len(lv.jobs) {
+		lv.jobIdx = 0
+	}
+	lv.status = ""
+	lv.followGen++
+	return tea.Batch(lv.load(), followTick(lv.followGen))
+}
+
+func (lv *logViewer) resize(width, height int) {
+	lv.viewport.Width, lv.viewport.Height = width, height
+	lv.render()
+}
+
+// load reads the current source in the background.
+func (lv logViewer) load() tea.Cmd {
+	if !lv.jobMode {
+		return func() tea.Msg {
+			return logEventsMsg{events: loadLogEvents()}
+		}
+	}
+	if len(lv.jobs) == 0 {
+		return func() tea.Msg { return logEventsMsg{err: fmt.Errorf("no jobs in history")} }
+	}
+	job := lv.jobs[lv.jobIdx]
+	return func() tea.Msg {
+		text, err := fetchJobLogs(job)
+		if err != nil {
+			return logEventsMsg{err: err}
+		}
+		return logEventsMsg{events: trainerLogEvents(text)}
+	}
+}
+
+func followTick(gen int) tea.Cmd {
+	return tea.Tick(logFollowInterval, func(time.Time) tea.Msg { return logFollowMsg{gen} })
+}
+
+// trainerLogEvents turns a trainer job log into events, deriving the level
+// from its [INFO]/[ERROR]/[SUCCESS] markers.
+func trainerLogEvents(text string) []eventlog.Event {
+	var events []eventlog.Event
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		level := eventlog.Info
+		if strings.HasPrefix(line, "[ERROR]") {
+			level = eventlog.Error
+		}
+		events = append(events, eventlog.Event{Level: level, Type: "trainer", Message: line})
+	}
+	return events
+}
+
+func (lv *logViewer) setEvents(events []eventlog.Event) {
+	lv.events = events
+	seen := map[string]bool{}
+	lv.types = nil
+	for _, e := range events {
+		if !seen[e.Type] {
+			seen[e.Type] = true
+			lv.types = append(lv.types, e.Type)
+		}
+	}
+	sort.Strings(lv.types)
+	if lv.typeIdx > len(lv.types) {
+		lv.typeIdx = 0
+	}
+	lv.render()
+}
+
+func (lv logViewer) typeFilter() string {
+	if lv.typeIdx == 0 || lv.typeIdx > len(lv.types) {
+		return ""
+	}
+	return lv.types[lv.typeIdx-1]
+}
+
+// visible applies the filters to e. Events without a time, such as the
+// lines of a trainer log, are not subject to the time range.
+func (lv logViewer) visible(e eventlog.Event) bool {
+	if min := levelFilters[lv.levelIdx]; min != "" && levelRank(e.Level) < levelRank(min) {
+		return false
+	}
+	if t := lv.typeFilter(); t != "" && e.Type != t {
+		return false
+	}
+	if span := timeRanges[lv.rangeIdx].span; span > 0 && !e.Time.IsZero() && time.Since(e.Time) > span {
+		return false
+	}
+	return true
+}
+
+// render applies the filters and search highlighting to the viewport.
+func (lv *logViewer) render() {
+	var lines []string
+	lv.matches = nil
+	var re *regexp.Regexp
+	if lv.query != "" {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(lv.query))
+	}
+	for _, e := range lv.events {
+		if !lv.visible(e) {
+			continue
+		}
+		line := e.String()
+		if re != nil && re.MatchString(line) {
+			lv.matches = append(lv.matches, len(lines))
+			line = highlight(line, re)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = []string{"(no matching log entries)"}
+	}
+	lv.viewport.SetContent(strings.Join(lines, "\n"))
+	if lv.follow {
+		lv.viewport.GotoBottom()
+	}
+}
+
+// highlight marks every match of re in line. Matching the original line,
+// rather than a lowercased copy whose byte offsets may differ, keeps the
+// marks on rune boundaries.
+func highlight(line string, re *regexp.Regexp) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(line, -1) {
+		b.WriteString(line[last:m[0]])
+		b.WriteString(matchStyle.Render(line[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+func (lv *logViewer) jumpToMatch(delta int) {
+	if len(lv.matches) == 0 {
+		return
+	}
+	lv.matchIdx = (lv.matchIdx + delta + len(lv.matches)) % len(lv.matches)
+	lv.follow = false
+	lv.viewport.SetYOffset(lv.matches[lv.matchIdx])
+}
+
+// update handles keys while the log view is open. It reports whether the
+// key was consumed, so that the caller can handle ESC and clearing.
+func (lv logViewer) update(msg tea.KeyMsg) (logViewer, tea.Cmd, bool) {
+	if lv.searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			lv.searching = false
+		case tea.KeyEnter:
+			lv.searching = false
+			lv.query = lv.input
+			lv.matchIdx = -1
+			lv.render()
+			lv.jumpToMatch(1)
+		case tea.KeyBackspace:
+			_, size := utf8.DecodeLastRuneInString(lv.input)
+			lv.input = lv.input[:len(lv.input)-size]
+		case tea.KeyRunes, tea.KeySpace:
+			lv.input += msg.String()
+		}
+		return lv, nil, true
+	}
+
+	switch msg.String() {
+	case "/":
+		lv.searching = true
+		lv.input = ""
+	case "n":
+		lv.jumpToMatch(1)
+	case "N":
+		lv.jumpToMatch(-1)
+	case "g", "home":
+		lv.follow = false
+		lv.viewport.GotoTop()
+	case "G", "end":
+		lv.viewport.GotoBottom()
+	case "f":
+		lv.follow = !lv.follow
+		if lv.follow {
+			lv.viewport.GotoBottom()
+			lv.followGen++
+			return lv, tea.Batch(lv.load(), followTick(lv.followGen)), true
+		}
+	case "l":
+		lv.levelIdx = (lv.levelIdx + 1) % len(levelFilters)
+		lv.render()
+	case "t":
+		lv.typeIdx = (lv.typeIdx + 1) % (len(lv.types) + 1)
+		lv.render()
+	case "r":
+		lv.rangeIdx = (lv.rangeIdx + 1) % len(timeRanges)
+		lv.render()
+	case "s":
+		lv.jobMode = !lv.jobMode
+		lv.typeIdx = 0
+		lv.events = nil
+		lv.status = ""
+		return lv, lv.load(), true
+	case "[", "]":
+		if lv.jobMode && len(lv.jobs) > 0 {
+			step := 1
+			if msg.String() == "[" {
+				step = len(lv.jobs) - 1
+			}
+			lv.jobIdx = (lv.jobIdx + step) % len(lv.jobs)
+			return lv, lv.load(), true
+		}
+	case "esc", "q", "c":
+		return lv, nil, false
+	default:
+		var cmd tea.Cmd
+		before := lv.viewport.YOffset
+		lv.viewport, cmd = lv.viewport.Update(msg)
+		if lv.viewport.YOffset < before {
+			lv.follow = false
+		}
+		return lv, cmd, true
+	}
+	return lv, nil, true
+}
+
+func (lv logViewer) view() string {
+	source := "Tune.log"
+	if lv.jobMode {
+		source = "trainer log"
+		if len(lv.jobs) > 0 {
+			job := lv.jobs[lv.jobIdx]
+			source = fmt.Sprintf("trainer log %s (%s)", shortID(job.ID), job.Output)
+		}
+	}
+	level := "all"
+	if l := levelFilters[lv.levelIdx]; l != "" {
+		level = string(l) + "+"
+	}
+	typ := lv.typeFilter()
+	if typ == "" {
+		typ = "all"
+	}
+	follow := "off"
+	if lv.follow {
+		follow = "on"
+	}
+	out := headerStyle.Render("Logs: "+source) + "\n"
+	out += fmt.Sprintf("level %s · type %s · range %s · follow %s · %3.0f%%\n\n",
+		level, typ, timeRanges[lv.rangeIdx].label, follow, lv.viewport.ScrollPercent()*100)
+	out += lv.viewport.View() + "\n\n"
+	switch {
+	case lv.searching:
+		out += "/" + lv.input + "█"
+	case lv.status != "":
+		out += lv.status
+	case lv.query != "":
+		out += fmt.Sprintf("search %q: %d matches (n/N to jump)", lv.query, len(lv.matches))
+	}
+	out += "\n[↑/↓/PgUp/PgDn] Scroll  [g/G] Top/Bottom  [f] Follow  [/] Search  [l] Level  [t] Type  [r] Range\n"
+	out += "[s] Tune.log/job log  [ [ ] ] Prev/next job  [c] Archive and clear  [ESC] Back"
+	return out
+}
+
+// handleMsg applies background log loads and follow ticks.
+func (lv logViewer) handleMsg(msg tea.Msg, active bool) (logViewer, tea.Cmd) {
+	switch msg := msg.(type) {
+	case logEventsMsg:
+		if msg.err != nil {
+			lv.status = "Error: " + msg.err.Error()
+			lv.setEvents(nil)
+			return lv, nil
+		}
+		lv.status = ""
+		lv.setEvents(msg.events)
+	case logFollowMsg:
+		if active && lv.follow && msg.gen == lv.followGen {
+			return lv, tea.Batch(lv.load(), followTick(lv.followGen))
+		}
+	}
+	return lv, nil
+}
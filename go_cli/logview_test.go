@@ -0,0 +1,99 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+)
+
+func TestHighlight(t *testing.T) {
+	mark := matchStyle.Render
+	tests := []struct {
+		line, query, want string
+	}{
+		{"Loading model mistral-7b", "MODEL", "Loading " + mark("model") + " mistral-7b"},
+		{"abc ABC aBc", "abc", mark("abc") + " " + mark("ABC") + " " + mark("aBc")},
+		{"no match here", "xyz", "no match here"},
+		// Lowercasing İ changes its length in bytes; offsets into a
+		// lowercased copy would split runes or run past the end.
+		{"İİİ abc", "abc", "İİİ " + mark("abc")},
+		{"İİİ abc", "i̇", "İİİ abc"},
+		{"straße STRASSE", "straße", mark("straße") + " STRASSE"},
+		{"[ERROR] (a+b)", "(a+b)", "[ERROR] " + mark("(a+b)")},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(tt.query))
+		if got := highlight(tt.line, re); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.line, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLogViewerVisible(t *testing.T) {
+	now := time.Now()
+	info := eventlog.Event{Time: now.Add(-2 * time.Hour), Level: eventlog.Info, Type: "job"}
+	warn := eventlog.Event{Time: now.Add(-10 * time.Minute), Level: eventlog.Warn, Type: "token"}
+	trainer := trainerLogEvents("[INFO] Loading model\n[ERROR] CUDA out of memory\n")
+	tests := []struct {
+		name                        string
+		levelIdx, typeIdx, rangeIdx int
+		event                       eventlog.Event
+		want                        bool
+	}{
+		{name: "no filters", event: info, want: true},
+		{name: "below minimum level", levelIdx: 2, event: info, want: false},
+		{name: "at minimum level", levelIdx: 2, event: warn, want: true},
+		{name: "other type", typeIdx: 2, event: info, want: false},
+		{name: "selected type", typeIdx: 2, event: warn, want: true},
+		{name: "older than range", rangeIdx: 1, event: info, want: false},
+		{name: "inside range", rangeIdx: 1, event: warn, want: true},
+		{name: "untimed trainer line in range", rangeIdx: 1, event: trainer[0], want: true},
+		{name: "untimed trainer error at error level", levelIdx: 3, rangeIdx: 3, event: trainer[1], want: true},
+		{name: "untimed trainer info at error level", levelIdx: 3, event: trainer[0], want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lv := logViewer{levelIdx: tt.levelIdx, typeIdx: tt.typeIdx, rangeIdx: tt.rangeIdx, types: []string{"job", "token"}}
+			if got := lv.visible(tt.event); got != tt.want {
+				t.Errorf("visible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobLogSurvivesTimeRange(t *testing.T) {
+	lv := newLogViewer(80, 10)
+	lv.jobMode = true
+	lv.rangeIdx = 2
+	lv.setEvents(trainerLogEvents("[INFO] Loading model\n[INFO] Starting training\n[SUCCESS] Saved\n"))
+	if got := lv.viewport.TotalLineCount(); got != 3 {
+		t.Errorf("job log shows %d lines with a 24h range, want 3", got)
+	}
+}
+
+func TestLogViewerSearchInput(t *testing.T) {
+	lv := newLogViewer(80, 10)
+	lv, _, _ = lv.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("loss")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyRunes, Runes: []rune("→é")},
+		{Type: tea.KeyBackspace},
+	}
+	for _, k := range keys {
+		lv, _, _ = lv.update(k)
+	}
+	if lv.input != "loss →" {
+		t.Errorf("input = %q, want %q", lv.input, "loss →")
+	}
+	for range 10 {
+		lv, _, _ = lv.update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	if lv.input != "" {
+		t.Errorf("input after clearing = %q", lv.input)
+	}
+}
@@ -2,42 +2,33 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/audit"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/config"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hub"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/profiles"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
 )
 
 // --- Splash Art ---
 func CreateNexaSplash() string {
-	nexaLines := []string{
-		"███╗   ██╗███████╗██╗  ██╗ █████╗      █████╗ ██╗   ██╗████████╗ ██████╗ ",
-		"████╗  ██║██╔════╝╚██╗██╔╝██╔══██╗    ██╔══██╗██║   ██║╚══██╔══╝██╔═══██╗",
-		"██╔██╗ ██║█████╗   ╚███╔╝ ███████║    ███████║██║   ██║   ██║   ██║   ██║",
-		"██║╚██╗██║██╔══╝   ██╔██╗ ██╔══██║    ██╔══██║██║   ██║   ██║   ██║   ██║",
-		"██║ ╚████║███████╗██╔╝ ██╗██║  ██║    ██║  ██║╚██████╔╝   ██║   ╚██████╔╝",
-		"╚═╝  ╚═══╝╚══════╝╚═╝  ╚═╝╚═╝  ╚═╝    ╚═╝  ╚═╝ ╚═════╝    ╚═╝    ╚═════╝ ",
-	}
-	green := lipgloss.Color("#39FF14")
-	box := lipgloss.NewStyle().
-		Border(lipgloss.ThickBorder()).
-		BorderForeground(green).
-		Background(lipgloss.Color("#101820")).
-		Padding(1, 4).
-		Align(lipgloss.Center)
-	style := lipgloss.NewStyle().Foreground(green).Bold(true)
-	var styledLines []string
-	for _, line := range nexaLines {
-		styledLines = append(styledLines, style.Render(line))
-	}
-	return box.Render(strings.Join(styledLines, "\n"))
+	return strings.Trim(nexaSplash.Render(), "\n")
 }
 
 // --- Constants and Styles ---
@@ -53,6 +44,12 @@ const (
 	confirmRun
 	modeSelect
 	clearLogs
+	artifacts
+	inspectView
+	jobHistoryView
+	modelCardView
+	publishView
+	vaultUnlock
 )
 
 var (
@@ -71,12 +68,18 @@ var (
 		Background(lipgloss.Color("#232946")).
 		Bold(true).
 		Padding(0, 1)
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF6B6B"))
 	loadingSlash = []string{"|", "/", "-", "\\"}
 	modelOptions   = []string{"mistral-7b", "llama-2-7b", "custom..."}
 	datasetOptions = []string{"local.jsonl", "hf-dataset", "custom..."}
 	modeOptions    = []string{"TUI Mode (modern)", "Classic CLI Mode"}
+	mainMenuOptions = []string{"Fine-tune Model", "View Logs", "Help", "Token Management", "Artifacts", "Job History"}
 )
 
+// appConfig holds the user's settings from config.json.
+var appConfig = loadConfig()
+
 // --- Types ---
 type state int
 
@@ -88,7 +91,19 @@ type model struct {
 	output          string
 	backendStatus   string
 	tokenStatus     string
-	tokenInput      string
+	tokenInput      textinput.Model
+	tokenError      string
+	tokenIdentity   *hub.Identity
+	tokenCheckErr   error
+	tokenRejected   bool
+	profiles        profiles.Set
+	profileIdx      int
+	profileEdit     profileEdit
+	pendingProfile  string
+	nameInput       textinput.Model
+	vaultInput      textinput.Model
+	vaultStatus     string
+	vaultReturn     state
 	selectedModel   int
 	selectedDataset int
 	outputName      string
@@ -99,13 +114,35 @@ type model struct {
 	loadingMenu     bool
 	cliStyle        lipgloss.Style
     local           bool
+	artifactFiles   []string
+	artifactMarks   map[string]bool
+	inspectTitle    string
+	inspectLines    []string
+	inspectScroll   int
+	inspectReturn   state
+	historyJobs     []history.Job
+	historyMarks    map[string]bool
+	historyStatus   string
+	cardJob         history.Job
+	cardText        string
+	publishJob      history.Job
+	publishRepo     string
+	publishing      bool
+	publishProgress hub.Progress
+	publishStatus   string
+	logView         logViewer
+	monitorStatus   string
 }
 
 // --- Model Initialization ---
 func initialModel() model {
-	return model{
+	m := model{
 		state:      modeSelect,
 		logs:       loadLogs(),
+		logView:    newLogViewer(96, 20),
+		tokenInput: newTokenInput(),
+		nameInput:  newNameInput(),
+		vaultInput: newPassphraseInput(),
 		loadingMenu: false,
 		loadingFrame: 0,
 		cliStyle: lipgloss.NewStyle().
@@ -113,11 +150,15 @@ func initialModel() model {
 			BorderForeground(lipgloss.Color("#4A90E2")).
 			Padding(1, 2),
 	}
+	if profileStore.Locked() {
+		m.startVaultUnlock(modeSelect)
+	}
+	return m
 }
 
 // --- Bubbletea Init ---
 func (m model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(resumeJobPolling(), loadMonitorStatus)
 }
 
 // --- Main Update ---
@@ -128,14 +169,105 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCLI(msg)
 		}
 		return m.updateTUI(msg)
+	case tea.WindowSizeMsg:
+		m.logView.resize(msg.Width-8, msg.Height-14)
+	case logEventsMsg, logFollowMsg:
+		var cmd tea.Cmd
+		m.logView, cmd = m.logView.handleMsg(msg, m.state == logs && m.mode == 0)
+		return m, cmd
+	case monitorStatusMsg:
+		m.monitorStatus = string(msg)
+		return m, refreshMonitorStatus()
 	case backendHealthMsg:
 		m.loading = false
 		m.backendStatus = string(msg)
-		m.appendLog("Backend health checked: " + m.backendStatus)
+	case jobSubmittedMsg:
+		m.confirmMsg = "Training job started with job ID: " + msg.ID
+		m.appendLog(eventlog.Info, eventlog.TypeJobSubmitted, "Training job submitted", eventlog.Fields{
+			"job_id": msg.ID, "model": msg.Model, "dataset": msg.Dataset, "output": msg.Output, "profile": msg.Profile,
+		})
+		return m, pollJobStatus(msg.ID)
+	case jobStatusMsg:
+		job, pending := applyJobStatus(msg)
+		if pending {
+			return m, pollJobStatus(msg.id)
+		}
+		m.appendLog(jobStatusLevel(msg.status), eventlog.TypeJobStatus, "Job "+msg.status, eventlog.Fields{
+			"job_id": msg.id, "model": job.Model, "status": msg.status, "duration_s": int(job.Duration().Seconds()),
+		})
+		if m.state == jobHistoryView {
+			m.historyJobs = loadHistory()
+		}
+		return m, notifyJobDone(job)
+	case jobNotifiedMsg:
+		// Printed above the view; the escapes ride along with the line.
+		return m, tea.Println(msg.escapes + msg.summary)
+	case publishProgressMsg:
+		m.publishProgress = msg.progress
+		return m, waitPublish(msg.ch)
+	case publishDoneMsg:
+		m.publishing = false
+		if msg.err != nil {
+			m.publishStatus = "Publish failed: " + msg.err.Error()
+			m.appendLog(eventlog.Error, eventlog.TypePublish, "Publish failed", eventlog.Fields{
+				"job_id": m.publishJob.ID, "repo": m.publishRepo, "error": msg.err.Error(),
+			})
+		} else {
+			m.publishStatus = "Published to " + msg.url
+			m.appendLog(eventlog.Info, eventlog.TypePublish, "Published adapter", eventlog.Fields{
+				"job_id": m.publishJob.ID, "repo": m.publishRepo, "url": msg.url,
+			})
+		}
+	case vaultUnlockedMsg:
+		if msg.err != nil {
+			m.vaultStatus = "Unlock failed: " + msg.err.Error()
+			return m, m.vaultInput.Focus()
+		}
+		m.vaultInput.Blur()
+		m.tokenStatus = msg.status
+		if m.vaultReturn == tokenMenu {
+			m.openTokenMenu()
+			m.tokenStatus = msg.status
+		} else {
+			m.state = m.vaultReturn
+		}
+	case profilesMsg:
+		m.profiles = msg.set
+		m.profileIdx = min(m.profileIdx, max(len(m.profiles.Profiles)-1, 0))
+		m.tokenStatus = msg.status
+		if msg.err != nil {
+			m.tokenStatus = "Error: " + msg.err.Error()
+		}
 	case tokenStatusMsg:
 		m.tokenStatus = string(msg)
-		m.tokenInput = ""
-		m.appendLog("Token status: " + m.tokenStatus)
+		m.appendLog(eventlog.Info, eventlog.TypeToken, "Token status: "+m.tokenStatus, nil)
+	case tokenCheckMsg:
+		m.tokenIdentity, m.tokenCheckErr, m.tokenRejected = msg.identity, msg.err, msg.rejected
+		if m.state == tokenMenu {
+			m.profiles = loadProfiles()
+			m.profileIdx = max(m.profiles.Index(m.profiles.Active), 0)
+		}
+		if msg.status != "" {
+			m.tokenStatus = msg.status
+		}
+		fields := eventlog.Fields{}
+		if msg.identity != nil {
+			fields["account"], fields["scope"] = msg.identity.Name, msg.identity.Scope()
+		}
+		if msg.err != nil {
+			fields["error"] = msg.err.Error()
+		}
+		if msg.rejected {
+			m.appendLog(eventlog.Warn, eventlog.TypeToken, "Token rejected by hub", fields)
+			if m.state == fineTune {
+				m.tokenStatus = "Enter your Hugging Face token:"
+				cmd := m.startTokenInput()
+				m.tokenError = "Token rejected by " + appConfig.HubURL + ": invalid or expired"
+				return m, cmd
+			}
+		} else if msg.status != "" {
+			m.appendLog(eventlog.Info, eventlog.TypeToken, "Token status: "+msg.status, fields)
+		}
 	case tickMsg:
 		if m.loading {
 			m.loadingFrame = (m.loadingFrame + 1) % len(loadingSlash)
@@ -175,13 +307,14 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.menuIdx = 0
 				return m, nil
 			} else { // CLI Mode
-				appendLogFile("Launching CLI mode (cli.py)")
+				logEvent(eventlog.Info, eventlog.TypeMode, "Launching CLI mode (cli.py)", nil)
 				go func() {
 					cmd := exec.Command("python", "cli.py")
 					cmd.Stdout = os.Stdout
 					cmd.Stderr = os.Stderr
 					cmd.Stdin = os.Stdin
 					_ = cmd.Run()
+					closeEventLog()
 					os.Exit(0)
 				}()
 				return m, tea.Quit
@@ -194,9 +327,9 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "j", "down":
-			m.menuIdx = (m.menuIdx + 1) % 4
+			m.menuIdx = (m.menuIdx + 1) % len(mainMenuOptions)
 		case "k", "up":
-			m.menuIdx = (m.menuIdx + 3) % 4
+			m.menuIdx = (m.menuIdx + len(mainMenuOptions) - 1) % len(mainMenuOptions)
 		case "enter":
 			m.loadingMenu = true
 			m.loadingFrame = 0
@@ -206,59 +339,52 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.state = fineTune
 				m.loading = true
 				m.backendStatus = "pending" // Mark ping as in progress
-				m.appendLog("Started fine-tune session, pinging backend...")
+				m.appendLog(eventlog.Info, eventlog.TypeSessionStart, "Started fine-tune session, pinging backend...", nil)
 				return m, checkBackendHealthCmd()
 			case 1:
 				m.state = logs
 				m.showLog = true
-				m.logs = loadLogs()
-				return m, nil
+				return m, m.logView.open()
 			case 2:
 				m.state = help
 				return m, nil
 			case 3:
-				m.state = tokenMenu
-				m.tokenStatus = ""
+				m.openTokenMenu()
+				return m, nil
+			case 4:
+				m.state = artifacts
+				m.menuIdx = 0
+				m.artifactFiles = listArtifacts()
+				m.artifactMarks = map[string]bool{}
+				return m, nil
+			case 5:
+				m.state = jobHistoryView
+				m.menuIdx = 0
+				m.historyJobs = loadHistory()
+				m.historyMarks = map[string]bool{}
+				m.historyStatus = ""
 				return m, nil
 			}
 		case "esc":
 			m.state = modeSelect
 		}
 	case tokenMenu:
-		switch msg.String() {
-		case "esc", "q":
-			m.state = mainMenu
-			m.tokenInput = ""
-			return m, nil
-		case "1":
-			return m, getToken
-		case "2":
-			m.tokenInput = ""
-			m.tokenStatus = "Enter token: "
-			return m, nil
-		case "3":
-			return m, clearToken
-		}
-		if m.tokenStatus == "Enter token: " && msg.Type == tea.KeyRunes {
-			m.tokenInput += msg.String()
-			return m, nil
-		}
-		if m.tokenStatus == "Enter token: " && msg.Type == tea.KeyEnter {
-			return m, setToken(m.tokenInput)
-		}
+		return m.updateTokenMenu(msg)
+	case vaultUnlock:
+		return m.updateVaultUnlock(msg)
 	case fineTune:
-		// Allow ESC to exit anytime
-		if msg.String() == "esc" || msg.String() == "q" {
+		// Allow ESC to exit anytime; q is literal text while typing a token
+		if msg.String() == "esc" || (msg.String() == "q" && !m.tokenInput.Focused()) {
 			m.state = mainMenu
 			m.backendStatus = ""
 			m.tokenStatus = ""
-			m.tokenInput = ""
-			m.appendLog("Exited fine-tune session")
+			m.stopTokenInput()
+			m.appendLog(eventlog.Info, eventlog.TypeSessionExit, "Exited fine-tune session", nil)
 			return m, nil
 		}
 		// If no ping has been initiated, immediately ping the backend.
 		if m.backendStatus == "" {
-			m.appendLog("Pinging backend...")
+			m.appendLog(eventlog.Debug, eventlog.TypeHealthCheck, "Pinging backend...", nil)
 			m.backendStatus = "pending"
 			return m, checkBackendHealthCmd()
 		}
@@ -269,21 +395,25 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Once ping returns (backendStatus is not "pending") and no token prompt yet, set tokenStatus.
 		if m.tokenStatus == "" {
 			if strings.Contains(m.backendStatus, `"status":"ok"`) {
+				if p, ok := activeProfile(); ok {
+					m.tokenStatus = "Using token profile " + p.Name + "..."
+					return m, setToken(p.Token)
+				}
 				m.tokenStatus = "Enter your Hugging Face token:"
-			} else {
-				m.tokenStatus = "Backend unavailable. Press ESC to return."
+				return m, m.startTokenInput()
 			}
+			m.tokenStatus = "Backend unavailable. Press ESC to return."
 			return m, nil
 		}
-		// Handle token input if healthy.
-		if m.tokenStatus == "Enter your Hugging Face token:" {
-			if msg.Type == tea.KeyRunes {
-				m.tokenInput += msg.String()
-				return m, nil
-			}
-			if msg.Type == tea.KeyEnter && m.tokenInput != "" {
-				return m, setToken(m.tokenInput)
-			}
+		// Handle token input if healthy; the token becomes a new profile.
+		if m.tokenInput.Focused() {
+			return m, m.updateTokenInput(msg, func(token string) tea.Cmd {
+				if profileStore.Locked() {
+					return setToken(token)
+				}
+				set := loadProfiles()
+				return addProfile(set.UniqueName(defaultProfileName), token)
+			})
 		}
 		// When token is set successfully, switch to model selection.
 		if m.tokenStatus == "Token set successfully" {
@@ -291,8 +421,7 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.menuIdx = 0
 			m.backendStatus = ""
 			m.tokenStatus = ""
-			m.tokenInput = ""
-			m.appendLog("Token set, proceeding to model selection")
+			m.appendLog(eventlog.Info, eventlog.TypeToken, "Token set, proceeding to model selection", nil)
 			return m, nil
 		}
 	case modelSelect:
@@ -307,7 +436,7 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedModel = m.menuIdx
 			m.state = datasetSelect
 			m.menuIdx = 0
-			m.appendLog(fmt.Sprintf("Selected model: %s", modelOptions[m.selectedModel]))
+			m.appendLog(eventlog.Info, eventlog.TypeSelection, "Selected model", eventlog.Fields{"model": modelOptions[m.selectedModel]})
 		}
 	case datasetSelect:
 		switch msg.String() {
@@ -321,7 +450,7 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedDataset = m.menuIdx
 			m.state = outputName
 			m.outputName = ""
-			m.appendLog(fmt.Sprintf("Selected dataset: %s", datasetOptions[m.selectedDataset]))
+			m.appendLog(eventlog.Info, eventlog.TypeSelection, "Selected dataset", eventlog.Fields{"dataset": datasetOptions[m.selectedDataset]})
 		}
 	case outputName:
 		switch msg.Type {
@@ -335,7 +464,7 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.outputName != "" {
 				m.state = confirmRun
 				m.confirmMsg = ""
-				m.appendLog(fmt.Sprintf("Set output name: %s", m.outputName))
+				m.appendLog(eventlog.Info, eventlog.TypeSelection, "Set output name", eventlog.Fields{"output": m.outputName})
 			}
 		case tea.KeyEsc:
 			m.state = mainMenu
@@ -344,7 +473,7 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "y":
 			m.confirmMsg = "[TODO] Launching fine-tune job..."
-			m.appendLog("Confirmed fine-tune run")
+			m.appendLog(eventlog.Info, eventlog.TypeSelection, "Confirmed fine-tune run", nil)
 			return m, sendTrainRequest(m)
 		case "n", "esc":
 			m.state = mainMenu
@@ -354,18 +483,172 @@ func (m model) updateTUI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.state = mainMenu
 		}
 	case logs:
+		var cmd tea.Cmd
+		var handled bool
+		if m.logView, cmd, handled = m.logView.update(msg); handled {
+			return m, cmd
+		}
 		if msg.String() == "esc" || msg.String() == "q" {
 			m.state = mainMenu
 			m.showLog = false
 		}
-		if msg.String() == "c" {
+		if msg.String() == "c" && !m.logView.jobMode {
 			m.state = clearLogs
 			return m, clearLogFile()
 		}
 	case clearLogs:
 		m.state = logs
-		m.logs = loadLogs()
-		return m, nil
+		return m, m.logView.open()
+	case artifacts:
+		switch msg.String() {
+		case "esc", "q":
+			m.state = mainMenu
+			m.menuIdx = 4
+		case "j", "down":
+			if len(m.artifactFiles) > 0 {
+				m.menuIdx = (m.menuIdx + 1) % len(m.artifactFiles)
+			}
+		case "k", "up":
+			if len(m.artifactFiles) > 0 {
+				m.menuIdx = (m.menuIdx + len(m.artifactFiles) - 1) % len(m.artifactFiles)
+			}
+		case "r":
+			m.artifactFiles = listArtifacts()
+			m.artifactMarks = map[string]bool{}
+			m.menuIdx = 0
+		case " ":
+			if len(m.artifactFiles) > 0 {
+				f := m.artifactFiles[m.menuIdx]
+				m.artifactMarks[f] = !m.artifactMarks[f]
+			}
+		case "d":
+			if marked := m.markedArtifacts(); len(marked) == 2 {
+				m.state = inspectView
+				m.inspectScroll = 0
+				m.inspectTitle = "Adapter Diff"
+				m.inspectLines = diffArtifacts(marked[0], marked[1])
+				m.inspectReturn = artifacts
+			}
+		case "enter":
+			if len(m.artifactFiles) > 0 {
+				m.state = inspectView
+				m.inspectScroll = 0
+				m.inspectTitle = "Inspect"
+				m.inspectLines = inspectArtifact(m.artifactFiles[m.menuIdx])
+				m.inspectReturn = artifacts
+			}
+		}
+	case jobHistoryView:
+		switch msg.String() {
+		case "esc", "q":
+			m.state = mainMenu
+			m.menuIdx = 5
+		case "j", "down":
+			if len(m.historyJobs) > 0 {
+				m.menuIdx = (m.menuIdx + 1) % len(m.historyJobs)
+			}
+		case "k", "up":
+			if len(m.historyJobs) > 0 {
+				m.menuIdx = (m.menuIdx + len(m.historyJobs) - 1) % len(m.historyJobs)
+			}
+		case "r":
+			m.historyJobs = loadHistory()
+		case " ":
+			if len(m.historyJobs) > 0 {
+				id := m.historyJobs[m.menuIdx].ID
+				m.historyMarks[id] = !m.historyMarks[id]
+			}
+		case "e":
+			if len(m.historyJobs) > 0 {
+				m.historyStatus = exportReports(m.historyJobs[m.menuIdx])
+				m.appendLog(eventlog.Info, eventlog.TypeArtifact, m.historyStatus, eventlog.Fields{"job_id": m.historyJobs[m.menuIdx].ID})
+			}
+		case "m":
+			if len(m.historyJobs) > 0 {
+				job := m.historyJobs[m.menuIdx]
+				card, err := renderModelCard(job)
+				if err != nil {
+					m.historyStatus = "Model card failed: " + err.Error()
+					break
+				}
+				m.state = modelCardView
+				m.inspectScroll = 0
+				m.cardJob = job
+				m.cardText = card
+				m.historyStatus = ""
+			}
+		case "p":
+			if len(m.historyJobs) > 0 {
+				m.state = publishView
+				m.publishJob = m.historyJobs[m.menuIdx]
+				m.publishRepo = ""
+				m.publishStatus = ""
+				return m, checkPublishToken
+			}
+		case "c":
+			if marked := m.markedJobs(); len(marked) >= 2 {
+				m.state = inspectView
+				m.inspectScroll = 0
+				m.inspectTitle = "Run Comparison"
+				m.inspectLines = compareRuns(marked)
+				m.inspectReturn = jobHistoryView
+			}
+		}
+	case modelCardView:
+		switch msg.String() {
+		case "esc", "q":
+			m.state = jobHistoryView
+		case "j", "down":
+			m.inspectScroll = min(m.inspectScroll+1, strings.Count(m.cardText, "\n")-1)
+		case "k", "up":
+			m.inspectScroll = max(m.inspectScroll-1, 0)
+		case "s":
+			path, err := saveModelCard(m.cardJob, m.cardText)
+			if err != nil {
+				m.historyStatus = "Saving model card failed: " + err.Error()
+				m.appendLog(eventlog.Error, eventlog.TypeArtifact, "Saving model card failed", eventlog.Fields{"job_id": m.cardJob.ID, "error": err.Error()})
+			} else {
+				m.historyStatus = "Model card written to " + path
+				m.appendLog(eventlog.Info, eventlog.TypeArtifact, "Model card written", eventlog.Fields{"job_id": m.cardJob.ID, "path": path})
+			}
+			m.state = jobHistoryView
+		}
+	case publishView:
+		if m.publishing {
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.state = jobHistoryView
+		case tea.KeyRunes:
+			m.publishRepo += msg.String()
+			m.publishStatus = ""
+		case tea.KeyBackspace:
+			if len(m.publishRepo) > 0 {
+				m.publishRepo = m.publishRepo[:len(m.publishRepo)-1]
+			}
+		case tea.KeyEnter:
+			if m.publishRepo != "" {
+				m.publishing = true
+				m.publishStatus = ""
+				m.publishProgress = hub.Progress{}
+				m.appendLog(eventlog.Info, eventlog.TypePublish, "Publishing adapter", eventlog.Fields{"job_id": m.publishJob.ID, "repo": m.publishRepo})
+				return m, startPublish(filepath.Join(outputRoot, m.publishJob.Output), m.publishRepo)
+			}
+		}
+	case inspectView:
+		switch msg.String() {
+		case "esc", "q":
+			m.state = m.inspectReturn
+		case "j", "down":
+			if m.inspectScroll < len(m.inspectLines)-1 {
+				m.inspectScroll++
+			}
+		case "k", "up":
+			if m.inspectScroll > 0 {
+				m.inspectScroll--
+			}
+		}
 	}
 	return m, nil
 }
@@ -388,7 +671,7 @@ func (m model) updateCLI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			case 0:
 				m.state = fineTune
 				m.loading = true
-				m.appendLog("Started fine-tune session (CLI)")
+				m.appendLog(eventlog.Info, eventlog.TypeSessionStart, "Started fine-tune session (CLI)", nil)
 				return m, tea.Batch(checkBackendHealth, tickLoading(), tickMenuLoading())
 			case 1:
 				m.state = logs
@@ -422,27 +705,26 @@ func (m model) updateCLI(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 	case tokenMenu:
+		if m.tokenInput.Focused() {
+			if msg.Type == tea.KeyEsc {
+				m.stopTokenInput()
+				m.tokenStatus = ""
+				return m, nil
+			}
+			return m, m.updateTokenInput(msg, setToken)
+		}
 		switch msg.String() {
 		case "esc", "q":
 			m.state = mainMenu
-			m.tokenInput = ""
 			return m, nil
 		case "1":
 			return m, getToken
 		case "2":
-			m.tokenInput = ""
 			m.tokenStatus = "Enter token: "
-			return m, nil
+			return m, m.startTokenInput()
 		case "3":
 			return m, clearToken
 		}
-		if m.tokenStatus == "Enter token: " {
-			if msg.Type == tea.KeyRunes {
-				m.tokenInput += msg.String()
-			} else if msg.Type == tea.KeyEnter {
-				return m, setToken(m.tokenInput)
-			}
-		}
 	}
 	return m, nil
 }
@@ -477,9 +759,17 @@ func sendTrainRequest(m model) tea.Cmd {
 		if err != nil {
 			return backendHealthMsg(fmt.Sprintf("Error marshaling JSON: %v", err))
 		}
+		profile, err := pushActiveToken()
+		if err != nil {
+			logEvent(eventlog.Error, eventlog.TypeJobError, "Error pushing token", eventlog.Fields{"profile": profile, "error": err.Error()})
+			return backendHealthMsg(fmt.Sprintf("Error sending token: %v", err))
+		}
 
-		resp, err := http.Post("http://localhost:8770/train", "application/json", bytes.NewBuffer(jsonData))
+		client := trainerClient()
+		resp, err := client.WithTimeout(submitTimeout).Post(context.Background(), "/train", "application/json", bytes.NewBuffer(jsonData))
 		if err != nil {
+			trainerFailed(client)
+			logEvent(eventlog.Error, eventlog.TypeJobError, "Error sending train request", eventlog.Fields{"error": err.Error()})
 			return backendHealthMsg(fmt.Sprintf("Error sending request: %v", err))
 		}
 		defer resp.Body.Close()
@@ -495,37 +785,32 @@ func sendTrainRequest(m model) tea.Cmd {
 			return backendHealthMsg(fmt.Sprintf("Error unmarshaling response: %v", err))
 		}
 
-		return backendHealthMsg(fmt.Sprintf("Training job started with job ID: %s", trainResponse.JobID))
+		recordAudit(audit.JobSubmit, trainResponse.JobID, map[string]string{
+			"model":   trainRequest.Model,
+			"dataset": trainRequest.Dataset,
+			"output":  trainRequest.Output,
+			"profile": profile,
+			"trainer": client.Endpoint,
+		})
+		return jobSubmittedMsg(recordJob(trainRequest, trainResponse.JobID, profile))
 	}
 }
 
 // --- Backend Health Check ---
-// Simplify to use a single known endpoint for quick ping.
+// checkBackendHealth probes the configured trainer endpoints in order and
+// reports the first healthy one, or the last failure.
 func checkBackendHealth() tea.Msg {
-	const endpoint = "http://localhost:8770/health"
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		msg := fmt.Sprintf("Backend not available: %v", err)
-		appendLogFile("Backend health checked: " + msg)
-		return backendHealthMsg(msg)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		msg := fmt.Sprintf("Unexpected status code %d from %s", resp.StatusCode, endpoint)
-		appendLogFile("Backend health checked: " + msg)
-		return backendHealthMsg(msg)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		msg := fmt.Sprintf("Error reading response: %v", err)
-		appendLogFile("Backend health checked: " + msg)
-		return backendHealthMsg(msg)
+	_, probes := selectTrainer()
+	if len(probes) == 0 {
+		return backendHealthMsg("Backend not available: no trainer endpoint configured")
 	}
 	// This is what we expect:
-	// Backend health checked: {"status":"ok","components":{"session_server":"ok","trainer":"ok"},"timestamp":"..."} (endpoint: http://localhost:8770/health)
-	logMsg := fmt.Sprintf("Backend health checked: %s (endpoint: %s)", string(body), endpoint)
-	appendLogFile(logMsg)
-	return backendHealthMsg(string(body))
+	// {"status":"ok","components":{"session_server":"ok","trainer":"ok"},"timestamp":"..."}
+	h := probes[len(probes)-1]
+	if h.Err != nil {
+		return backendHealthMsg(fmt.Sprintf("Backend not available: %v", h.Err))
+	}
+	return backendHealthMsg(string(h.Body))
 }
 
 func checkBackendHealthCmd() tea.Cmd {
@@ -535,22 +820,70 @@ func checkBackendHealthCmd() tea.Cmd {
 }
 
 // --- Token Management ---
+const tokenCheckTimeout = 10 * time.Second
+
+// tokenCheckMsg reports a token change or lookup together with the hub
+// account behind the token. identity is nil when there is no token or it
+// could not be verified; err explains why. An empty status leaves the
+// current token status untouched.
+type tokenCheckMsg struct {
+	status   string
+	identity *hub.Identity
+	rejected bool
+	err      error
+}
+
+// whoAmI validates token against the configured hub.
+func whoAmI(token string) (hub.Identity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCheckTimeout)
+	defer cancel()
+	return hub.NewClient(appConfig.HubURL, token).WhoAmI(ctx)
+}
+
+// checkToken builds the message for a token that is already in use.
+func checkToken(status, token string) tokenCheckMsg {
+	id, err := whoAmI(token)
+	if err != nil {
+		return tokenCheckMsg{status: status, rejected: errors.Is(err, hub.ErrUnauthorized), err: err}
+	}
+	return tokenCheckMsg{status: status, identity: &id}
+}
+
 func getToken() tea.Msg {
 	token := os.Getenv("HF_TOKEN")
 	if token == "" {
-		return tokenStatusMsg("No token found")
+		return tokenCheckMsg{status: "No token found"}
 	}
-	return tokenStatusMsg("Token: " + token[:4] + "..." + token[len(token)-4:])
+	return checkToken("Token: "+redact.Token(token), token)
 }
 
+// setToken accepts token only if the hub does not reject it. When the hub
+// cannot be reached the token is kept but reported as unverified.
 func setToken(token string) tea.Cmd {
 	return func() tea.Msg {
-		err := os.Setenv("HF_TOKEN", token)
-		if err != nil {
+		redact.AddSecret(token)
+		id, err := whoAmI(token)
+		if errors.Is(err, hub.ErrUnauthorized) {
+			return tokenCheckMsg{status: "Token rejected by " + appConfig.HubURL, rejected: true, err: err}
+		}
+		if err := os.Setenv("HF_TOKEN", token); err != nil {
 			return tokenStatusMsg("Failed to set token: " + err.Error())
 		}
-		return tokenStatusMsg("Token set successfully")
+		recordAudit(audit.TokenSet, "HF_TOKEN", tokenAuditDetails(token, id, err))
+		if err != nil {
+			return tokenCheckMsg{status: "Token set successfully", err: err}
+		}
+		return tokenCheckMsg{status: "Token set successfully", identity: &id}
+	}
+}
+
+// checkPublishToken looks up the identity of the token used for pushing.
+func checkPublishToken() tea.Msg {
+	token, err := hubToken()
+	if err != nil {
+		return tokenCheckMsg{err: err}
 	}
+	return checkToken("", token)
 }
 
 func clearToken() tea.Msg {
@@ -558,7 +891,31 @@ func clearToken() tea.Msg {
 	if err != nil {
 		return tokenStatusMsg("Failed to clear token: " + err.Error())
 	}
-	return tokenStatusMsg("Token cleared")
+	recordAudit(audit.TokenClear, "HF_TOKEN", nil)
+	return tokenCheckMsg{status: "Token cleared"}
+}
+
+// identityView describes the account behind the current token.
+func (m model) identityView() string {
+	switch {
+	case m.tokenIdentity != nil:
+		return "Account: " + m.tokenIdentity.String()
+	case m.tokenCheckErr != nil && !m.tokenRejected:
+		return "Token not verified: " + m.tokenCheckErr.Error()
+	}
+	return ""
+}
+
+// writeTokenWarning is shown before actions that need a write token.
+func (m model) writeTokenWarning() string {
+	switch {
+	case m.tokenRejected:
+		return errorStyle.Render("Warning: the hub rejected the current token")
+	case m.tokenIdentity != nil && !m.tokenIdentity.CanWrite():
+		return errorStyle.Render("Warning: " + m.tokenIdentity.Name + "'s token has " + m.tokenIdentity.Scope() +
+			" access; publishing needs a write token")
+	}
+	return ""
 }
 
 // --- Loading Spinner ---
@@ -581,11 +938,12 @@ func (m model) splash() string {
 	return CreateNexaSplash()
 }
 
+// View renders the current screen with any secrets masked.
 func (m model) View() string {
 	if m.mode == 1 {
-		return m.cliView()
+		return redact.String(m.cliView())
 	}
-	return m.tuiView()
+	return redact.String(m.tuiView())
 }
 
 func (m model) tuiView() string {
@@ -607,21 +965,22 @@ func (m model) tuiView() string {
 		out += "\n[q] Quit"
 		return out
 	case mainMenu:
-		menu := []string{"Fine-tune Model", "View Logs", "Help", "Token Management"}
 		out := CreateNexaSplash() + "\n\n"
 		out += headerStyle.Render("Main Menu") + "\n\n"
-		for i, item := range menu {
+		for i, item := range mainMenuOptions {
 			if i == m.menuIdx {
 				out += selectedStyle.Render("> " + item) + "\n"
 			} else {
 				out += "  " + item + "\n"
 			}
 		}
+		if m.monitorStatus != "" {
+			out += "\n" + m.monitorStatus + "\n"
+		}
 		out += "\n[q] Quit"
 		return out
 	case tokenMenu:
-		return boxStyle.Render("[Token Management] (ESC/q to return)\n" +
-			"1. Get Token\n2. Set Token\n3. Clear Token\n\n" + m.tokenStatus + m.tokenInput)
+		return boxStyle.Render(m.tokenMenuView())
 	case fineTune:
 		var display string
 		// While ping in progress, show loading message.
@@ -630,29 +989,35 @@ func (m model) tuiView() string {
 		} else if m.tokenStatus == "" {
 			display = "Backend check complete."
 		} else {
-			if m.tokenStatus == "Enter your Hugging Face token:" {
-				display = m.tokenStatus + " " + m.tokenInput
+			if m.tokenInput.Focused() {
+				display = m.tokenStatus + "\n" + m.tokenInputView()
+			} else if id := m.identityView(); id != "" {
+				display = m.tokenStatus + "\n" + id + "\n\nPress any key to continue."
 			} else {
 				display = m.tokenStatus
 			}
 		}
 		return boxStyle.Render("[Fine-tune] (ESC/q to return)\n\n" + display)
 	case logs:
-		logsContent := strings.Join(m.logs, "\n")
-		return boxStyle.Render("[Logs] (ESC/q to return, c to clear)\n\n" +
-			logsContent)
+		return boxStyle.Render(m.logView.view())
 	case help:
 		return boxStyle.Render("[Help] (ESC/q to return)\n\n" +
 			"Commands:\n" +
 			"  ↑/↓ or j/k   - Navigate menu\n" +
 			"  Enter        - Select\n" +
 			"  q or ESC     - Back/Exit\n" +
-			"  1/2/3        - Token actions in Token Management\n\n" +
+			"  a/r/d/v      - Add, rename, delete, verify token profiles\n" +
+			"  L / u        - Lock / unlock the token vault (nexa vault init creates it)\n\n" +
 			"Workflow:\n" +
 			"  1. Fine-tune: Checks backend, prompts for HF token if needed, then launches session\n" +
-			"  2. Token Management: Set, get, or clear your Hugging Face token\n" +
-			"  3. Logs: View backend logs (future)\n" +
-			"  4. Help: Show this help screen\n")
+			"  2. Token Management: Named token profiles; Enter makes one active for new jobs\n" +
+			"  3. Logs: Scroll, search (/) and filter Tune.log or a job's trainer log (s switches)\n" +
+			"  4. Help: Show this help screen\n" +
+			"  5. Artifacts: Browse and inspect adapters in nexa_output/\n" +
+			"     (Space marks two adapters, d diffs their runs)\n" +
+			"  6. Job History: Past jobs; mark two or more with Space, c compares them,\n" +
+			"     e exports a report of the selected job, m previews its model card,\n" +
+			"     p publishes its adapter to the Hugging Face Hub\n")
 	case modelSelect:
 		out := headerStyle.Render("Select Model") + "\n\n"
 		for i, opt := range modelOptions {
@@ -683,6 +1048,18 @@ func (m model) tuiView() string {
 				modelOptions[m.selectedModel], datasetOptions[m.selectedDataset], m.outputName, m.confirmMsg))
 	case clearLogs:
 		return boxStyle.Render("[Logs Cleared]")
+	case artifacts:
+		return boxStyle.Render(m.artifactsView())
+	case inspectView:
+		return boxStyle.Render(m.inspectView())
+	case jobHistoryView:
+		return boxStyle.Render(m.historyView())
+	case modelCardView:
+		return boxStyle.Render(m.modelCardView())
+	case publishView:
+		return boxStyle.Render(m.publishView())
+	case vaultUnlock:
+		return boxStyle.Render(m.vaultUnlockView())
 	}
 	return ""
 }
@@ -719,49 +1096,110 @@ func (m model) cliView() string {
 }
 
 // --- Logging ---
-func (m *model) appendLog(entry string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logEntry := fmt.Sprintf("[%s] %s", timestamp, entry)
-	m.logs = append(m.logs, logEntry)
-	appendLogFile(logEntry)
+// logFilePath is the structured event log shared by the TUI and commands,
+// in the XDG state directory unless configured otherwise.
+var logFilePath = appConfig.Log.Path
+
+// eventLog writes this process's events, tagged with its session ID.
+var eventLog = newEventLog()
+
+func newEventLog() *eventlog.Logger {
+	l := eventlog.New(logFilePath, eventlog.NewSessionID())
+	l.Rotation = appConfig.Log.RotateConfig
+	return l
 }
 
-func appendLogFile(entry string) {
-	f, err := os.OpenFile("Tune.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
+// closeEventLog writes out pending events before exit, warning when some
+// could not be saved.
+func closeEventLog() {
+	if err := eventLog.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: writing %s: %v\n", logFilePath, err)
 	}
-	defer f.Close()
-	f.WriteString(entry + "\n")
 }
 
-func loadLogs() []string {
-	data, err := ioutil.ReadFile("Tune.log")
+func (m *model) appendLog(level eventlog.Level, typ, msg string, fields eventlog.Fields) {
+	e := eventLog.Log(level, typ, msg, fields)
+	m.logs = append(m.logs, e.String())
+}
+
+func logEvent(level eventlog.Level, typ, msg string, fields eventlog.Fields) {
+	eventLog.Log(level, typ, msg, fields)
+}
+
+// loadLogEvents reads Tune.log and parses every line into an event. Pending
+// events are flushed first so the view includes everything logged so far.
+func loadLogEvents() []eventlog.Event {
+	eventLog.Flush()
+	data, err := ioutil.ReadFile(logFilePath)
 	if err != nil {
-		return []string{}
+		return nil
 	}
 	lines := strings.Split(string(data), "\n")
 	if len(lines) > 0 && lines[len(lines)-1] == "" {
 		lines = lines[:len(lines)-1]
 	}
+	events := make([]eventlog.Event, len(lines))
+	for i, line := range lines {
+		events[i] = eventlog.Parse(line)
+	}
+	return events
+}
+
+// loadLogs renders every Tune.log event as a human-readable line.
+func loadLogs() []string {
+	events := loadLogEvents()
+	lines := make([]string, len(events))
+	for i, e := range events {
+		lines[i] = e.String()
+	}
 	return lines
 }
 
 // --- Clear Logs ---
+// clearLogFile archives the current log, keeping history within the
+// retention limit instead of destroying it.
 func clearLogFile() tea.Cmd {
 	return func() tea.Msg {
-		err := os.Truncate("Tune.log", 0)
+		eventLog.Flush()
+		archive, err := eventlog.Rotate(logFilePath, appConfig.Log.RotateConfig)
 		if err != nil {
 			return backendHealthMsg("Failed to clear log file: " + err.Error())
 		}
-		return backendHealthMsg("Log file cleared")
+		logEvent(eventlog.Info, eventlog.TypeLogs, "Log file archived", eventlog.Fields{"archive": archive})
+		return backendHealthMsg("Log file archived to " + archive)
+	}
+}
+
+// jobStatusLevel picks the log level for a terminal job status.
+func jobStatusLevel(status string) eventlog.Level {
+	if status == history.StatusFinished {
+		return eventlog.Info
+	}
+	return eventlog.Error
+}
+
+// --- Config ---
+func loadConfig() config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: using default settings: %v\n", err)
 	}
+	redact.AddSecret(cfg.Secrets...)
+	redact.AddSecret(os.Getenv("HF_TOKEN"))
+	return cfg
 }
 
 // --- Main ---
 func main() {
+	if len(os.Args) > 1 {
+		code := runCommand(os.Args[1], os.Args[2:])
+		closeEventLog()
+		os.Exit(code)
+	}
 	p := tea.NewProgram(initialModel())
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	closeEventLog()
+	if err != nil {
 		fmt.Printf("Error running program: %v", err)
 		os.Exit(1)
 	}
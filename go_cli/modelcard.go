@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/modelcard"
+)
+
+// --- Model Card Command ---
+func runCard(args []string) error {
+	fset := flag.NewFlagSet("card", flag.ContinueOnError)
+	printOnly := fset.Bool("print", false, "print the card instead of writing README.md")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 {
+		return errors.New("usage: nexa card [--print] <job>")
+	}
+	job, err := jobHistory.Get(fset.Arg(0))
+	if err != nil {
+		return err
+	}
+	card, err := renderModelCard(job)
+	if err != nil {
+		return err
+	}
+	if *printOnly {
+		fmt.Print(card)
+		return nil
+	}
+	path, err := saveModelCard(job, card)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Model card written to", path)
+	return nil
+}
+
+func renderModelCard(job history.Job) (string, error) {
+	tmpl, err := modelcard.LoadTemplate()
+	if err != nil {
+		return "", err
+	}
+	return modelcard.Render(tmpl, modelcard.NewData(job, filepath.Join(outputRoot, job.Output)))
+}
+
+func saveModelCard(job history.Job, card string) (string, error) {
+	dir := filepath.Join(outputRoot, job.Output)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, modelcard.FileName)
+	return path, os.WriteFile(path, []byte(card), 0o644)
+}
+
+// --- Model Card Preview ---
+func (m model) modelCardView() string {
+	lines := strings.Split(strings.TrimRight(m.cardText, "\n"), "\n")
+	start := min(m.inspectScroll, len(lines)-1)
+	end := min(start+inspectPageSize, len(lines))
+	out := headerStyle.Render("Model Card: "+m.cardJob.Output) + "\n\n"
+	out += strings.Join(lines[start:end], "\n")
+	out += fmt.Sprintf("\n\n[%d-%d of %d]  [↑/↓] Scroll  [s] Save README.md  [ESC] Cancel", start+1, end, len(lines))
+	if m.historyStatus != "" {
+		out += "\n" + m.historyStatus
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+// Package modelcard renders a Hugging Face model card (README.md) for a
+// finished adapter from its job metadata and training state.
+package modelcard
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/adapter"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+// FileName is the name the Hub expects for a model card.
+const FileName = "README.md"
+
+// DefaultTemplate is used when the user has not provided their own.
+//
+//go:embed default.md.tmpl
+var DefaultTemplate string
+
+// Param is one training hyperparameter.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Data is what a model card template is executed with.
+type Data struct {
+	Job             history.Job
+	Name            string
+	BaseModel       string
+	Library         string
+	License         string
+	Tags            []string
+	Datasets        []string
+	Hyperparameters []Param
+	FinalLoss       string
+	BestLoss        string
+	Steps           int
+}
+
+// NewData collects the model card fields for job from its run directory.
+func NewData(job history.Job, runDir string) Data {
+	d := Data{
+		Job:       job,
+		Name:      job.Output,
+		BaseModel: job.Model,
+		Library:   "transformers",
+		License:   "other",
+		Tags:      []string{"nexa-auto", "fine-tuned"},
+		FinalLoss: "n/a",
+		BestLoss:  "n/a",
+	}
+	if isHubDataset(job.Dataset) {
+		d.Datasets = []string{job.Dataset}
+	}
+	if r, err := adapter.Load(runDir); err == nil && r.Config != nil {
+		d.Library = "peft"
+		d.Tags = append(d.Tags, "lora")
+		if base, ok := r.Config["base_model_name_or_path"].(string); ok && base != "" {
+			d.BaseModel = base
+		}
+		for _, key := range []string{"peft_type", "r", "lora_alpha", "lora_dropout", "target_modules"} {
+			if v, ok := r.Config[key]; ok {
+				d.Hyperparameters = append(d.Hyperparameters, Param{key, jsonValue(v)})
+			}
+		}
+	}
+	if s, err := trainerstate.Find(runDir); err == nil {
+		d.Steps = s.GlobalStep
+		d.Hyperparameters = append(d.Hyperparameters,
+			Param{"num_train_epochs", fmt.Sprint(s.NumTrainEpochs)},
+			Param{"train_batch_size", fmt.Sprint(s.TrainBatchSize)},
+			Param{"logging_steps", fmt.Sprint(s.LoggingSteps)},
+			Param{"save_steps", fmt.Sprint(s.SaveSteps)},
+		)
+		if v, ok := s.FinalLoss(); ok {
+			d.FinalLoss = fmt.Sprintf("%.4f", v)
+		}
+		if v, ok := s.BestLoss(); ok {
+			d.BestLoss = fmt.Sprintf("%.4f", v)
+		}
+	}
+	return d
+}
+
+// TemplatePath is where a user template overrides DefaultTemplate.
+func TemplatePath() string {
+	return filepath.Join(xdg.ConfigHome(), "templates", "model_card.md.tmpl")
+}
+
+// LoadTemplate returns the user's template if present, else the default.
+func LoadTemplate() (string, error) {
+	data, err := os.ReadFile(TemplatePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultTemplate, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Render executes tmpl with d.
+func Render(tmpl string, d Data) (string, error) {
+	t, err := template.New("model_card").Funcs(template.FuncMap{"yaml": yamlString}).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// hubDatasetID matches owner/name dataset IDs as used on the Hub.
+var hubDatasetID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*/[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// isHubDataset reports whether name is an owner/name Hub dataset ID rather
+// than a local file or a placeholder such as "hf-dataset".
+func isHubDataset(name string) bool {
+	if !hubDatasetID.MatchString(name) {
+		return false
+	}
+	switch filepath.Ext(name) {
+	case ".json", ".jsonl", ".csv", ".txt", ".parquet":
+		return false
+	}
+	return true
+}
+
+func jsonValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// yamlString quotes s as a YAML double-quoted scalar.
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,151 @@
+package modelcard
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/adapter"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeAdapterRun writes a LoRA run with an empty weights file and a
+// trainer state to a new directory.
+func writeAdapterRun(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, adapter.ConfigFile),
+		`{"base_model_name_or_path": "mistralai/Mistral-7B-v0.1", "peft_type": "LORA", "r": 8, "lora_alpha": 16, "target_modules": ["q_proj", "v_proj"]}`)
+	header := "{}"
+	weights := append(binary.LittleEndian.AppendUint64(nil, uint64(len(header))), header...)
+	writeFile(t, filepath.Join(dir, "adapter_model.safetensors"), string(weights))
+	writeFile(t, filepath.Join(dir, trainerstate.FileName), `{"global_step": 20, "num_train_epochs": 1,
+		"train_batch_size": 4, "logging_steps": 10, "save_steps": 10,
+		"log_history": [{"step": 10, "loss": 1.5}, {"step": 20, "loss": 0.75}]}`)
+	return dir
+}
+
+func TestRenderDefault(t *testing.T) {
+	job := history.Job{ID: "job-1", Model: "mistral-7b", Dataset: "tatsu-lab/alpaca", Output: "my-adapter"}
+	card, err := Render(DefaultTemplate, NewData(job, writeAdapterRun(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	front, body, ok := strings.Cut(strings.TrimPrefix(card, "---\n"), "\n---\n")
+	if !strings.HasPrefix(card, "---\n") || !ok {
+		t.Fatalf("card has no front matter:\n%s", card)
+	}
+	wantFront := `base_model: "mistralai/Mistral-7B-v0.1"
+library_name: "peft"
+license: "other"
+tags:
+- "nexa-auto"
+- "fine-tuned"
+- "lora"
+datasets:
+- "tatsu-lab/alpaca"`
+	if front != wantFront {
+		t.Errorf("front matter:\n%s\nwant:\n%s", front, wantFront)
+	}
+	for _, want := range []string{
+		"# my-adapter",
+		"on the tatsu-lab/alpaca dataset",
+		"- r: 8",
+		`- target_modules: ["q_proj","v_proj"]`,
+		"- train_batch_size: 4",
+		"- Final training loss: 0.7500",
+		"- Best training loss: 0.7500",
+		"- Steps: 20",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("card body lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRenderWithoutArtifacts(t *testing.T) {
+	// A run without adapter or trainer state still gets a valid card,
+	// with YAML special characters quoted.
+	job := history.Job{Model: `odd: "model"`, Dataset: "local.jsonl", Output: "run"}
+	card, err := Render(DefaultTemplate, NewData(job, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`base_model: "odd: \"model\""`, `library_name: "transformers"`, "- Final training loss: n/a"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("card lacks %q:\n%s", want, card)
+		}
+	}
+	if strings.Contains(card, "datasets:") {
+		t.Errorf("local dataset listed in the front matter:\n%s", card)
+	}
+}
+
+func TestIsHubDataset(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"tatsu-lab/alpaca", true},
+		{"HuggingFaceH4/ultrachat_200k", true},
+		{"org/data.v2", true},
+		{"", false},
+		{"hf-dataset", false},
+		{"custom...", false},
+		{"imdb", false},
+		{"local.jsonl", false},
+		{"data/train.jsonl", false},
+		{"data/train.csv", false},
+		{"/abs/path/train", false},
+		{"./data/train", false},
+		{"a/b/c", false},
+		{"org/", false},
+		{"org name/data", false},
+	}
+	for _, tt := range tests {
+		if got := isHubDataset(tt.name); got != tt.want {
+			t.Errorf("isHubDataset(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpl, err := LoadTemplate()
+	if err != nil || tmpl != DefaultTemplate {
+		t.Fatalf("LoadTemplate() without a user template = %.40q, %v", tmpl, err)
+	}
+
+	writeFile(t, TemplatePath(), "license: {{yaml .License}}\nmodel: {{.Name}}\n")
+	tmpl, err = LoadTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, err := Render(tmpl, NewData(history.Job{Output: "run-a"}, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "license: \"other\"\nmodel: run-a\n"; card != want {
+		t.Errorf("custom card = %q, want %q", card, want)
+	}
+
+	if _, err := Render("{{.Missing}}", Data{}); err == nil {
+		t.Error("Render() with an unknown field succeeded")
+	}
+	if _, err := Render("{{.Name", Data{}); err == nil {
+		t.Error("Render() of a malformed template succeeded")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/monitor"
+)
+
+// --- Monitor Command ---
+// runMonitor runs the metrics daemon in the foreground until SIGINT or
+// SIGTERM. Flags override the "monitor" section of config.json.
+func runMonitor(args []string) error {
+	cfg := appConfig.Monitor
+	fset := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	logPath := fset.String("log", appConfig.Log.Path, "Tune.log to tail")
+	listen := fset.String("listen", cfg.Listen, "address to serve /metrics on")
+	interval := fset.Duration("interval", cfg.Interval(), "time between probes and log polls")
+	trainer := fset.String("trainer", strings.Join(appConfig.TrainerEndpoints(), ","), "comma-separated trainer endpoints; empty to skip probing")
+	sessionURLs := fset.String("session", strings.Join(appConfig.SessionEndpoints(), ","), "comma-separated session server endpoints; empty to skip probing")
+	output := fset.String("output", cfg.OutputRoot, "directory the trainer writes job output to")
+	historyPath := fset.String("history", cfg.HistoryPath, "file the probe history behind /status is kept in")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 0 {
+		return errors.New("usage: nexa monitor [--log path] [--listen addr] [--interval 5s] [--trainer urls] [--session urls] [--output dir] [--history path]")
+	}
+	cfg.Listen = *listen
+	cfg.IntervalSeconds = int(interval.Round(time.Second) / time.Second)
+	cfg.OutputRoot = *output
+	cfg.HistoryPath = *historyPath
+
+	var targets []monitor.Target
+	for _, t := range []monitor.Target{
+		{Name: "trainer", Endpoints: splitList(*trainer)},
+		{Name: "session", Endpoints: splitList(*sessionURLs)},
+	} {
+		if len(t.Endpoints) > 0 {
+			targets = append(targets, t)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logger := log.New(os.Stderr, "nexa monitor: ", log.LstdFlags)
+	return monitor.New(cfg, *logPath, targets, logger).Run(ctx)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,317 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+)
+
+// Alert kinds.
+const (
+	AlertDown      = "down"
+	AlertFlapping  = "flapping"
+	AlertRecovered = "recovered"
+)
+
+// DefaultAlertBody is posted when no webhook body template is configured.
+const DefaultAlertBody = `{"kind": {{json .Kind}}, "backend": {{json .Backend}}, "endpoint": {{json .Endpoint}}, "time": {{json .Time}}, "summary": {{json .Summary}}}`
+
+// alertTimeout bounds each webhook request and command hook, so a hung
+// channel holds up the alerts queued behind it for at most this long.
+const alertTimeout = 10 * time.Second
+
+// alertQueueSize is how many alerts may wait for delivery. Alerts raised
+// while the queue is full are logged but not delivered.
+const alertQueueSize = 32
+
+// AlertConfig sets the alert rules and where alerts are delivered. Alerts
+// are always logged; WebhookURL and Command add further channels.
+type AlertConfig struct {
+	// Failures is how many consecutive failed probes of an endpoint raise
+	// a down alert.
+	Failures int `json:"failures"`
+	// An endpoint that changes state FlapChanges times within
+	// FlapWindowSeconds is flapping: one alert is sent and its down and
+	// recovery alerts are held back until it has been stable for the
+	// window. Zero FlapChanges disables flap detection.
+	FlapChanges       int `json:"flap_changes"`
+	FlapWindowSeconds int `json:"flap_window_seconds"`
+	// CooldownSeconds is the least time between two down or flapping
+	// alerts for the same endpoint. A problem that persists past the
+	// cooldown is still reported.
+	CooldownSeconds int `json:"cooldown_seconds"`
+	// WebhookURL receives a POST with WebhookBody rendered as a template
+	// over the Alert.
+	WebhookURL  string `json:"webhook_url"`
+	WebhookBody string `json:"webhook_body"`
+	// Command is run with sh -c and the alert in NEXA_* variables.
+	Command string `json:"command"`
+}
+
+// DefaultAlertConfig returns the rules used when none are configured.
+func DefaultAlertConfig() AlertConfig {
+	return AlertConfig{
+		Failures:          3,
+		FlapChanges:       4,
+		FlapWindowSeconds: 600,
+		CooldownSeconds:   1800,
+	}
+}
+
+func (c AlertConfig) flapWindow() time.Duration {
+	return time.Duration(c.FlapWindowSeconds) * time.Second
+}
+
+func (c AlertConfig) cooldown() time.Duration {
+	return time.Duration(c.CooldownSeconds) * time.Second
+}
+
+// Alert is a change in an endpoint's health worth telling someone about.
+type Alert struct {
+	Kind     string    `json:"kind"`
+	Backend  string    `json:"backend"`
+	Endpoint string    `json:"endpoint"`
+	Time     time.Time `json:"time"`
+	// Reason is the error or status of the last failed probe.
+	Reason string `json:"reason,omitempty"`
+	// Failures is the number of consecutive failed probes.
+	Failures int `json:"failures,omitempty"`
+	// Changes is the number of state changes within the flap window.
+	Changes int `json:"changes,omitempty"`
+	// Downtime is how long the endpoint was down before it recovered;
+	// zero when it recovered from flapping.
+	Downtime time.Duration `json:"downtime,omitempty"`
+}
+
+// Summary is a one-line human-readable description of the alert.
+func (a Alert) Summary() string {
+	name := a.Backend + " " + a.Endpoint
+	switch a.Kind {
+	case AlertDown:
+		return fmt.Sprintf("%s is down after %d failed probes: %s", name, a.Failures, a.Reason)
+	case AlertFlapping:
+		return fmt.Sprintf("%s is flapping: %d state changes", name, a.Changes)
+	case AlertRecovered:
+		if a.Downtime == 0 {
+			return name + " is stable and healthy again"
+		}
+		return fmt.Sprintf("%s recovered after %s down", name, a.Downtime.Round(time.Second))
+	}
+	return name + " " + a.Kind
+}
+
+// endpointAlerts is the alert state of one endpoint.
+type endpointAlerts struct {
+	seen     bool
+	failures int
+	// downSince is the time of the first failure in the current run.
+	downSince time.Time
+	reason    string
+	// firing is set once a down or flapping alert was sent, until the
+	// matching recovery.
+	firing   bool
+	flapping bool
+	// changes are the state changes within the flap window.
+	changes     []time.Time
+	lastProblem time.Time
+}
+
+// alerter applies the alert rules to probe results and delivers the
+// alerts. Delivery happens in deliver, off the probe loop.
+type alerter struct {
+	cfg    AlertConfig
+	log    *log.Logger
+	http   *http.Client
+	states map[string]*endpointAlerts
+	queue  chan Alert
+}
+
+func newAlerter(cfg AlertConfig, logger *log.Logger) *alerter {
+	return &alerter{
+		cfg:    cfg,
+		log:    logger,
+		http:   &http.Client{Timeout: alertTimeout},
+		states: map[string]*endpointAlerts{},
+		queue:  make(chan Alert, alertQueueSize),
+	}
+}
+
+// observe updates the state of the probed endpoint and returns the alert
+// it raises, if any.
+func (a *alerter) observe(name string, h backend.Health, now time.Time) (Alert, bool) {
+	key := name + " " + h.Endpoint
+	s, ok := a.states[key]
+	if !ok {
+		s = &endpointAlerts{}
+		a.states[key] = s
+	}
+	up := h.OK()
+	switch {
+	case !s.seen:
+		a.logInitial(name, h)
+	case up != (s.failures == 0):
+		s.changes = append(s.changes, now)
+	}
+	s.seen = true
+	s.changes = slices.DeleteFunc(s.changes, func(t time.Time) bool { return now.Sub(t) > a.cfg.flapWindow() })
+	if up {
+		s.failures = 0
+	} else {
+		if s.failures == 0 {
+			s.downSince = now
+		}
+		s.failures++
+		s.reason = h.Status
+		if h.Err != nil {
+			s.reason = h.Err.Error()
+		}
+	}
+
+	alert := Alert{Backend: name, Endpoint: h.Endpoint, Time: now}
+	if s.flapping {
+		if len(s.changes) > 0 {
+			return alert, false
+		}
+		// Stable for a whole window: report the state it settled in.
+		s.flapping = false
+		if up {
+			s.firing = false
+			alert.Kind = AlertRecovered
+			return alert, true
+		}
+		alert.Kind, alert.Reason, alert.Failures = AlertDown, s.reason, s.failures
+		return alert, true
+	}
+	coolingDown := !s.lastProblem.IsZero() && now.Sub(s.lastProblem) < a.cfg.cooldown()
+	switch {
+	case a.cfg.FlapChanges > 0 && len(s.changes) >= a.cfg.FlapChanges && !coolingDown:
+		s.flapping, s.firing, s.lastProblem = true, true, now
+		alert.Kind, alert.Changes = AlertFlapping, len(s.changes)
+		return alert, true
+	case !up && !s.firing && s.failures >= max(a.cfg.Failures, 1) && !coolingDown:
+		s.firing, s.lastProblem = true, now
+		alert.Kind, alert.Reason, alert.Failures = AlertDown, s.reason, s.failures
+		return alert, true
+	case up && s.firing:
+		s.firing = false
+		alert.Kind, alert.Downtime = AlertRecovered, now.Sub(s.downSince)
+		return alert, true
+	}
+	return alert, false
+}
+
+// logInitial logs the state an endpoint is first seen in, so the log shows
+// what the alerts that follow are relative to.
+func (a *alerter) logInitial(name string, h backend.Health) {
+	if h.OK() {
+		a.log.Printf("[INFO] %s healthy: %s (%s)", name, h.Endpoint, h.Latency.Round(time.Millisecond))
+		return
+	}
+	reason := h.Status
+	if h.Err != nil {
+		reason = h.Err.Error()
+	}
+	a.log.Printf("[WARN] %s unhealthy: %s: %s", name, h.Endpoint, reason)
+}
+
+// raise logs the alert and queues it for the webhook and command hook
+// without waiting for them.
+func (a *alerter) raise(al Alert) {
+	a.log.Printf("[ALERT] %s", al.Summary())
+	if a.cfg.WebhookURL == "" && a.cfg.Command == "" {
+		return
+	}
+	select {
+	case a.queue <- al:
+	default:
+		a.log.Printf("[ERROR] Alert queue full, not delivering: %s", al.Summary())
+	}
+}
+
+// deliver sends queued alerts until ctx is cancelled.
+func (a *alerter) deliver(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case al := <-a.queue:
+			a.send(ctx, al)
+		}
+	}
+}
+
+// send delivers the alert on every channel, logging delivery failures.
+func (a *alerter) send(ctx context.Context, al Alert) {
+	var errs []error
+	if a.cfg.WebhookURL != "" {
+		errs = append(errs, a.webhook(ctx, al))
+	}
+	if a.cfg.Command != "" {
+		errs = append(errs, a.command(ctx, al))
+	}
+	if err := errors.Join(errs...); err != nil {
+		a.log.Printf("[ERROR] Delivering alert: %v", err)
+	}
+}
+
+func (a *alerter) webhook(ctx context.Context, al Alert) error {
+	body := a.cfg.WebhookBody
+	if body == "" {
+		body = DefaultAlertBody
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(body)
+	if err != nil {
+		return fmt.Errorf("webhook body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, al); err != nil {
+		return fmt.Errorf("webhook body template: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.WebhookURL, &buf)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *alerter) command(ctx context.Context, al Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, alertTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", a.cfg.Command)
+	cmd.Env = append(os.Environ(),
+		"NEXA_ALERT="+al.Kind,
+		"NEXA_BACKEND="+al.Backend,
+		"NEXA_ENDPOINT="+al.Endpoint,
+		"NEXA_REASON="+al.Reason,
+		"NEXA_SUMMARY="+al.Summary(),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command hook: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
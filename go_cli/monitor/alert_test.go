@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+)
+
+// run feeds probes one minute apart, up as given, and returns the kinds of
+// the alerts raised.
+func run(a *alerter, start time.Time, up ...bool) []string {
+	var kinds []string
+	for i, ok := range up {
+		h := backend.Health{Endpoint: "http://localhost:8770", Status: "ok"}
+		if !ok {
+			h.Status, h.Err = "unavailable", errors.New("connection refused")
+		}
+		if alert, raised := a.observe("trainer", h, start.Add(time.Duration(i)*time.Minute)); raised {
+			kinds = append(kinds, alert.Kind)
+		}
+	}
+	return kinds
+}
+
+func repeat(up bool, n int) []bool {
+	return slices.Repeat([]bool{up}, n)
+}
+
+func TestAlerts(t *testing.T) {
+	start := time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		probe []bool
+		want  []string
+	}{
+		{
+			name:  "overnight outage",
+			probe: slices.Concat(repeat(true, 5), repeat(false, 600), repeat(true, 5)),
+			want:  []string{AlertDown, AlertRecovered},
+		},
+		{
+			name:  "blips below the failure threshold",
+			probe: slices.Concat(repeat(true, 20), repeat(false, 2), repeat(true, 20), repeat(false, 2), repeat(true, 20)),
+			want:  nil,
+		},
+		{
+			name: "flapping",
+			probe: slices.Concat(repeat(true, 3), []bool{false, true, false, true, false, true, false, true},
+				repeat(true, 15)),
+			want: []string{AlertFlapping, AlertRecovered},
+		},
+		{
+			name: "flapping then down",
+			probe: slices.Concat(repeat(true, 3), []bool{false, true, false, true},
+				repeat(false, 30), repeat(true, 3)),
+			want: []string{AlertFlapping, AlertDown, AlertRecovered},
+		},
+		{
+			name: "second outage within the cooldown",
+			probe: slices.Concat(repeat(false, 5), repeat(true, 30), repeat(false, 10), repeat(true, 30),
+				repeat(false, 30)),
+			want: []string{AlertDown, AlertRecovered, AlertDown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultAlertConfig()
+			cfg.FlapWindowSeconds = 10 * 60
+			cfg.CooldownSeconds = 60 * 60
+			a := newAlerter(cfg, log.New(io.Discard, "", 0))
+			if got := run(a, start, tt.probe...); !slices.Equal(got, tt.want) {
+				t.Errorf("alerts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertSummary(t *testing.T) {
+	a := Alert{Kind: AlertRecovered, Backend: "trainer", Endpoint: "http://localhost:8770", Downtime: 8*time.Hour + 1500*time.Millisecond}
+	if got, want := a.Summary(), "trainer http://localhost:8770 recovered after 8h0m2s down"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestAlertDelivery(t *testing.T) {
+	bodies := make(chan string, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := DefaultAlertConfig()
+	cfg.WebhookURL = srv.URL
+	cfg.WebhookBody = `{{.Kind}} {{.Backend}}`
+	a := newAlerter(cfg, log.New(io.Discard, "", 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.deliver(ctx)
+		close(done)
+	}()
+
+	// The webhook hangs on the first alert; raising more must not block
+	// the caller, and alerts beyond the queue are dropped.
+	raised := make(chan struct{})
+	go func() {
+		for range alertQueueSize + 5 {
+			a.raise(Alert{Kind: AlertDown, Backend: "trainer"})
+		}
+		close(raised)
+	}()
+	select {
+	case <-raised:
+	case <-time.After(time.Second):
+		t.Fatal("raise blocked on a hung webhook")
+	}
+	select {
+	case body := <-bodies:
+		if body != "down trainer" {
+			t.Errorf("webhook body = %q", body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert was not delivered")
+	}
+
+	// Cancelling the run aborts the hung request and stops delivery.
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deliver did not stop when its context was cancelled")
+	}
+}
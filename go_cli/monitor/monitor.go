@@ -0,0 +1,263 @@
+// Package monitor runs the Nexa backend monitor: it probes the trainer and
+// session servers on an interval, tails Tune.log and the trainer's job logs
+// into Prometheus metrics and serves them, along with the probe history,
+// over HTTP until its context is cancelled.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/logparser"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/uptime"
+)
+
+const (
+	// ProbeTimeout bounds a single /health probe.
+	ProbeTimeout = 2 * time.Second
+	// shutdownTimeout bounds draining in-flight scrapes on shutdown.
+	shutdownTimeout = 5 * time.Second
+	// saveInterval is how often the probe history is saved when no
+	// endpoint changed state.
+	saveInterval = time.Minute
+)
+
+// Config holds the monitor settings that can be kept in config.json.
+// Endpoints and the log path come from the rest of the config.
+type Config struct {
+	// Listen is the address the metrics server binds to.
+	Listen string `json:"listen"`
+	// IntervalSeconds is the time between probes and log polls.
+	IntervalSeconds int `json:"interval_seconds"`
+	// OutputRoot is where the trainer writes job output directories and
+	// its train_<job_id>.log files.
+	OutputRoot string `json:"output_root"`
+	// HistoryPath is the probe history file behind /status and the TUI's
+	// status line.
+	HistoryPath string `json:"history_path"`
+	// Alerts sets when endpoint health changes are alerted and how.
+	Alerts AlertConfig `json:"alerts"`
+}
+
+// DefaultConfig returns the settings used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		Listen:          ":8080",
+		IntervalSeconds: 5,
+		OutputRoot:      logparser.DefaultOutputRoot,
+		HistoryPath:     uptime.DefaultPath(),
+		Alerts:          DefaultAlertConfig(),
+	}
+}
+
+// Interval returns the probe interval, at least one second.
+func (c Config) Interval() time.Duration {
+	return max(time.Duration(c.IntervalSeconds)*time.Second, time.Second)
+}
+
+// Target is one backend to probe: its name, e.g. "trainer", and the
+// endpoints it may be reached at.
+type Target struct {
+	Name      string
+	Endpoints []string
+}
+
+// Monitor probes targets and exports metrics.
+type Monitor struct {
+	cfg     Config
+	targets []Target
+	log     *log.Logger
+
+	reg     *prometheus.Registry
+	metrics *logparser.Metrics
+	parser  *logparser.Parser
+	jobLogs *logparser.JobLogs
+	alerts  *alerter
+
+	// mu guards history, which is read by /status while probes run.
+	mu       sync.Mutex
+	history  *uptime.History
+	lastSave time.Time
+}
+
+// New returns a monitor tailing logPath and probing targets. Events are
+// logged to logger. The probe history is loaded from cfg.HistoryPath; an
+// unreadable history is logged and started afresh.
+func New(cfg Config, logPath string, targets []Target, logger *log.Logger) *Monitor {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	metrics := logparser.NewMetrics(reg)
+	parser := logparser.New(logPath, metrics)
+	parser.OutputRoot = cfg.OutputRoot
+	parser.HealthFromLog = len(targets) == 0
+	history, err := uptime.Load(cfg.HistoryPath)
+	if err != nil {
+		logger.Printf("[WARN] Starting a new probe history: %v", err)
+	}
+	return &Monitor{
+		cfg:     cfg,
+		targets: targets,
+		log:     logger,
+		reg:     reg,
+		metrics: metrics,
+		parser:  parser,
+		jobLogs: logparser.NewJobLogs(cfg.OutputRoot, metrics),
+		alerts:  newAlerter(cfg.Alerts, logger),
+		history: history,
+	}
+}
+
+// Handler serves /metrics and /status.
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/status", m.serveStatus)
+	return mux
+}
+
+// serveStatus writes the probe history summary as JSON.
+func (m *Monitor) serveStatus(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	status := m.history.Status(time.Now())
+	m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(status)
+}
+
+// Run serves metrics and probes on every interval until ctx is cancelled,
+// then stops the ticker and alert delivery, drains the HTTP server and
+// returns nil. It fails early if the listen address cannot be bound.
+func (m *Monitor) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", m.cfg.Listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: m.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+	m.log.Printf("Serving metrics on http://%s/metrics; probing every %s", ln.Addr(), m.cfg.Interval())
+	defer m.parser.Close()
+	defer m.jobLogs.Close()
+	defer m.save()
+
+	dctx, stopDelivery := context.WithCancel(ctx)
+	delivered := make(chan struct{})
+	go func() {
+		m.alerts.deliver(dctx)
+		close(delivered)
+	}()
+	defer func() {
+		stopDelivery()
+		<-delivered
+	}()
+
+	ticker := time.NewTicker(m.cfg.Interval())
+	defer ticker.Stop()
+	m.tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Print("Shutting down")
+			sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			return srv.Shutdown(sctx)
+		case err := <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+			m.tick(ctx)
+		}
+	}
+}
+
+// tick probes every target and reads new log events and job log lines.
+func (m *Monitor) tick(ctx context.Context) {
+	if len(m.targets) > 0 {
+		m.probe(ctx)
+	}
+	if _, err := m.parser.Poll(); err != nil {
+		m.log.Printf("[ERROR] Reading %s: %v", m.parser.Path(), err)
+	}
+	if err := m.jobLogs.Poll(time.Now()); err != nil {
+		m.log.Printf("[ERROR] Reading job logs: %v", err)
+	}
+}
+
+// probe checks every endpoint of every target, raises alerts and records
+// the results in the history, saving it when an endpoint changed state or
+// a while has passed. backend_available is 1 when the trainer has a
+// healthy endpoint.
+func (m *Monitor) probe(ctx context.Context) {
+	changed := false
+	for _, t := range m.targets {
+		up := false
+		for _, e := range t.Endpoints {
+			h := m.check(ctx, e)
+			if ctx.Err() != nil {
+				return
+			}
+			now := time.Now()
+			m.metrics.ObserveProbe(t.Name, h)
+			if alert, ok := m.alerts.observe(t.Name, h, now); ok {
+				m.alerts.raise(alert)
+			}
+			m.mu.Lock()
+			changed = m.history.Record(t.Name, h, now) || changed
+			m.mu.Unlock()
+			up = up || h.OK()
+		}
+		if t.Name == "trainer" {
+			m.metrics.BackendAvailable.Set(boolFloat(up))
+		}
+	}
+	if changed || time.Since(m.lastSave) >= saveInterval {
+		m.save()
+	}
+}
+
+// save prunes the history and writes it to disk.
+func (m *Monitor) save() {
+	if len(m.targets) == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history.Prune(time.Now())
+	if err := m.history.Save(m.cfg.HistoryPath); err != nil {
+		m.log.Printf("[ERROR] Saving probe history: %v", err)
+	}
+	m.lastSave = time.Now()
+}
+
+func (m *Monitor) check(ctx context.Context, endpoint string) backend.Health {
+	c, err := backend.New(endpoint, ProbeTimeout)
+	if err != nil {
+		return backend.Health{Endpoint: endpoint, Status: "invalid", Err: err}
+	}
+	return c.CheckHealth(ctx)
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+// Package notify tells the user that a fine-tune job has finished, through
+// any combination of a terminal bell, an OSC 9 desktop notification, a JSON
+// webhook and a local command hook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookBody is posted when no body template is configured.
+const DefaultWebhookBody = `{"job_id": {{json .JobID}}, "status": {{json .Status}}, "output_dir": {{json .OutputDir}}, "duration_seconds": {{.Duration.Seconds}}, "final_loss": {{json .FinalLoss}}}`
+
+// hookTimeout bounds webhook requests and command hooks.
+const hookTimeout = 30 * time.Second
+
+// Config selects the notification channels.
+type Config struct {
+	// Bell rings the terminal bell.
+	Bell bool `json:"bell"`
+	// Desktop emits an OSC 9 escape, which many terminals turn into a
+	// desktop notification.
+	Desktop bool `json:"desktop"`
+	// WebhookURL receives a POST with WebhookBody rendered as a template.
+	WebhookURL  string `json:"webhook_url"`
+	WebhookBody string `json:"webhook_body"`
+	// Command is run with sh -c and the event in NEXA_* variables.
+	Command string `json:"command"`
+}
+
+// Enabled reports whether any channel is configured.
+func (c Config) Enabled() bool {
+	return c.Bell || c.Desktop || c.WebhookURL != "" || c.Command != ""
+}
+
+// Event describes a job that reached a terminal status.
+type Event struct {
+	JobID     string        `json:"job_id"`
+	Status    string        `json:"status"`
+	OutputDir string        `json:"output_dir"`
+	Duration  time.Duration `json:"duration"`
+	FinalLoss *float64      `json:"final_loss"`
+}
+
+// Summary is a one-line human-readable description of the event.
+func (e Event) Summary() string {
+	loss := "n/a"
+	if e.FinalLoss != nil {
+		loss = fmt.Sprintf("%.4f", *e.FinalLoss)
+	}
+	return fmt.Sprintf("Nexa job %s %s after %s (loss %s, output %s)",
+		e.JobID, e.Status, e.Duration.Round(time.Second), loss, e.OutputDir)
+}
+
+// Escapes returns the bell and OSC 9 sequences enabled in c for ev, or ""
+// when neither is.
+func (c Config) Escapes(ev Event) string {
+	var s string
+	if c.Bell {
+		s += "\a"
+	}
+	if c.Desktop {
+		s += "\x1b]9;" + ev.Summary() + "\x07"
+	}
+	return s
+}
+
+// Notifier delivers events through the configured channels.
+type Notifier struct {
+	Config Config
+	// Terminal receives the bell and OSC 9 escapes. When it is nil they
+	// are not written, so a program that owns the terminal, such as the
+	// TUI, can emit Escapes itself between frames.
+	Terminal io.Writer
+	HTTP     *http.Client
+}
+
+// New returns a notifier for cfg without a Terminal.
+func New(cfg Config) *Notifier {
+	return &Notifier{Config: cfg, HTTP: &http.Client{Timeout: hookTimeout}}
+}
+
+// Send delivers ev on every configured channel and joins their errors.
+func (n *Notifier) Send(ev Event) error {
+	var errs []error
+	if esc := n.Config.Escapes(ev); esc != "" && n.Terminal != nil {
+		_, err := io.WriteString(n.Terminal, esc)
+		errs = append(errs, err)
+	}
+	if n.Config.WebhookURL != "" {
+		errs = append(errs, n.webhook(ev))
+	}
+	if n.Config.Command != "" {
+		errs = append(errs, n.command(ev))
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) webhook(ev Event) error {
+	body := n.Config.WebhookBody
+	if body == "" {
+		body = DefaultWebhookBody
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(body)
+	if err != nil {
+		return fmt.Errorf("webhook body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ev); err != nil {
+		return fmt.Errorf("webhook body template: %w", err)
+	}
+	resp, err := n.HTTP.Post(n.Config.WebhookURL, "application/json", &buf)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *Notifier) command(ev Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.Config.Command)
+	cmd.Env = append(os.Environ(),
+		"NEXA_JOB_ID="+ev.JobID,
+		"NEXA_JOB_STATUS="+ev.Status,
+		"NEXA_OUTPUT_DIR="+ev.OutputDir,
+		fmt.Sprintf("NEXA_DURATION_SECONDS=%.0f", ev.Duration.Seconds()),
+		"NEXA_FINAL_LOSS="+lossString(ev.FinalLoss),
+		"NEXA_SUMMARY="+ev.Summary(),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command hook: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func lossString(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%g", *v)
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
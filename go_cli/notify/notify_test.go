@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func event() Event {
+	loss := 0.4213
+	return Event{JobID: "job-1", Status: "finished", OutputDir: "/out/run \"a\"", Duration: 90 * time.Second, FinalLoss: &loss}
+}
+
+func TestSummary(t *testing.T) {
+	ev := event()
+	want := `Nexa job job-1 finished after 1m30s (loss 0.4213, output /out/run "a")`
+	if got := ev.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	ev.FinalLoss = nil
+	if got := ev.Summary(); !strings.Contains(got, "loss n/a") {
+		t.Errorf("Summary() without a loss = %q", got)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"bell", Config{Bell: true}, "\a"},
+		{"desktop", Config{Desktop: true}, "\x1b]9;" + event().Summary() + "\x07"},
+		{"both", Config{Bell: true, Desktop: true}, "\a\x1b]9;" + event().Summary() + "\x07"},
+		{"neither", Config{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Escapes(event()); got != tt.want {
+				t.Errorf("Escapes() = %q, want %q", got, tt.want)
+			}
+			var term bytes.Buffer
+			n := New(tt.cfg)
+			n.Terminal = &term
+			if err := n.Send(event()); err != nil {
+				t.Fatal(err)
+			}
+			if term.String() != tt.want {
+				t.Errorf("terminal got %q, want %q", term.String(), tt.want)
+			}
+		})
+	}
+
+	// Without a Terminal the escapes are left to the caller.
+	if err := New(Config{Bell: true, Desktop: true}).Send(event()); err != nil {
+		t.Errorf("Send() without a terminal: %v", err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "default body",
+			status: http.StatusOK,
+			want:   `{"job_id": "job-1", "status": "finished", "output_dir": "/out/run \"a\"", "duration_seconds": 90, "final_loss": 0.4213}`,
+		},
+		{
+			name:   "custom template",
+			body:   `{"text": {{json .Summary}}}`,
+			status: http.StatusNoContent,
+			want:   `{"text": "Nexa job job-1 finished after 1m30s (loss 0.4213, output /out/run \"a\")"}`,
+		},
+		{
+			name:    "invalid template",
+			body:    `{"text": {{.Summary}`,
+			wantErr: "webhook body template",
+		},
+		{
+			name:    "unknown field",
+			body:    `{{.Missing}}`,
+			wantErr: "webhook body template",
+		},
+		{
+			name:    "rejected",
+			status:  http.StatusBadRequest,
+			wantErr: "unexpected status code 400",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				got, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := New(Config{WebhookURL: srv.URL, WebhookBody: tt.body}).Send(event())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Send() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("body is not valid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	cfg := Config{Command: `env | grep '^NEXA_' | sort > "$OUT"`}
+	t.Setenv("OUT", out)
+	if err := New(cfg).Send(event()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"NEXA_DURATION_SECONDS=90",
+		"NEXA_FINAL_LOSS=0.4213",
+		"NEXA_JOB_ID=job-1",
+		"NEXA_JOB_STATUS=finished",
+		`NEXA_OUTPUT_DIR=/out/run "a"`,
+		"NEXA_SUMMARY=" + event().Summary(),
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("hook environment:\n%s\nwant:\n%s", data, want)
+	}
+
+	err = New(Config{Command: "echo disk full >&2; exit 3"}).Send(event())
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("failing hook error = %v, want its output", err)
+	}
+}
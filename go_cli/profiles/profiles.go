@@ -0,0 +1,330 @@
+// Package profiles stores named Hugging Face tokens, such as a personal and
+// an organization token, and remembers which one is active.
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/vault"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+// maxNameLen keeps profile names short enough for the token menu.
+const maxNameLen = 32
+
+var (
+	// ErrNotFound is returned when no profile has the given name.
+	ErrNotFound = errors.New("profile not found")
+	// ErrExists is returned when a profile name is already taken.
+	ErrExists = errors.New("profile already exists")
+	// ErrLocked is returned when an encrypted store has not been unlocked.
+	ErrLocked = errors.New("token vault is locked")
+	// ErrReadOnly is returned when saving to a plain profiles file; tokens
+	// are only written to disk inside the vault.
+	ErrReadOnly = errors.New("plain profiles file is read-only; create a vault to save tokens")
+)
+
+// Profile is one named token.
+type Profile struct {
+	Name    string    `json:"name"`
+	Token   string    `json:"token"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Set is the full list of profiles and the name of the active one.
+type Set struct {
+	Active   string    `json:"active,omitempty"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// Index returns the position of the named profile, or -1.
+func (s *Set) Index(name string) int {
+	for i, p := range s.Profiles {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the named profile.
+func (s *Set) Get(name string) (Profile, error) {
+	if i := s.Index(name); i >= 0 {
+		return s.Profiles[i], nil
+	}
+	return Profile{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// ActiveProfile returns the active profile, if any.
+func (s *Set) ActiveProfile() (Profile, bool) {
+	p, err := s.Get(s.Active)
+	return p, err == nil
+}
+
+// Add appends a profile. The first profile becomes active.
+func (s *Set) Add(name, token string) error {
+	name = strings.TrimSpace(name)
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if s.Index(name) >= 0 {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	s.Profiles = append(s.Profiles, Profile{Name: name, Token: token, AddedAt: time.Now()})
+	if s.Active == "" {
+		s.Active = name
+	}
+	return nil
+}
+
+// Rename changes a profile's name, keeping it active if it was.
+func (s *Set) Rename(old, name string) error {
+	name = strings.TrimSpace(name)
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	i := s.Index(old)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, old)
+	}
+	if name == old {
+		return nil
+	}
+	if s.Index(name) >= 0 {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	s.Profiles[i].Name = name
+	if s.Active == old {
+		s.Active = name
+	}
+	return nil
+}
+
+// Delete removes a profile. Deleting the active profile leaves none active.
+func (s *Set) Delete(name string) error {
+	i := s.Index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	s.Profiles = append(s.Profiles[:i], s.Profiles[i+1:]...)
+	if s.Active == name {
+		s.Active = ""
+	}
+	return nil
+}
+
+// SetActive makes the named profile active.
+func (s *Set) SetActive(name string) error {
+	if s.Index(name) < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	s.Active = name
+	return nil
+}
+
+// UniqueName returns base, or base-2, base-3, ... if base is taken.
+func (s *Set) UniqueName(base string) string {
+	name := base
+	for i := 2; s.Index(name) >= 0; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// ValidateName rejects empty, overlong or multi-word names.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("profile name is empty")
+	case len(name) > maxNameLen:
+		return fmt.Errorf("profile name is longer than %d characters", maxNameLen)
+	case strings.ContainsAny(name, " \t\n"):
+		return errors.New("profile name must not contain spaces")
+	}
+	return nil
+}
+
+// Store holds the profiles. An encrypted store keeps them as JSON sealed in
+// a vault file and must be unlocked before use; without a vault they live
+// in memory only, so no token reaches the disk in plaintext.
+type Store struct {
+	path      string
+	encrypted bool
+	key       *vault.Key
+	// memory holds the profiles of an in-memory store.
+	memory *Set
+	mu     sync.Mutex
+}
+
+// PlainPath returns the unencrypted profiles file earlier versions wrote to
+// the XDG config directory.
+func PlainPath() string {
+	return filepath.Join(xdg.ConfigHome(), "profiles.json")
+}
+
+// VaultPath returns the encrypted profiles file in the XDG config directory.
+func VaultPath() string {
+	return filepath.Join(xdg.ConfigHome(), "profiles.vault")
+}
+
+// Open returns a read-only store backed by the plain profiles file at
+// path, for moving its profiles into a vault.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Memory returns a store that keeps set in memory and never writes it.
+func Memory(set Set) *Store {
+	set.Profiles = slices.Clone(set.Profiles)
+	return &Store{memory: &set}
+}
+
+// OpenVault returns a locked store backed by the encrypted file at path.
+func OpenVault(path string) *Store {
+	return &Store{path: path, encrypted: true}
+}
+
+// Path returns the file backing the store, or "" for an in-memory store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Encrypted reports whether the store is a vault.
+func (s *Store) Encrypted() bool {
+	return s.encrypted
+}
+
+// Locked reports whether the store is an encrypted vault without its key.
+func (s *Store) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encrypted && s.key == nil
+}
+
+// Unlock derives the vault key from passphrase. If the vault file does not
+// exist yet, a new key is created for it.
+func (s *Store) Unlock(passphrase []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.encrypted {
+		return nil
+	}
+	data, err := vault.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		key, err := vault.NewKey(passphrase)
+		if err != nil {
+			return err
+		}
+		s.key = key
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	key, plaintext, err := vault.Unseal(data, passphrase)
+	if err != nil {
+		return err
+	}
+	clear(plaintext)
+	s.key.Wipe()
+	s.key = key
+	return nil
+}
+
+// Lock forgets the vault key; the store is unusable until unlocked again.
+func (s *Store) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key.Wipe()
+	s.key = nil
+}
+
+// Load returns the stored profiles; a missing file yields an empty set.
+func (s *Store) Load() (Set, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Update applies fn to the stored profiles and saves the result unless fn
+// fails.
+func (s *Store) Update(fn func(*Set) error) (Set, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set, err := s.load()
+	if err != nil {
+		return set, err
+	}
+	if err := fn(&set); err != nil {
+		return set, err
+	}
+	return set, s.save(set)
+}
+
+func (s *Store) load() (Set, error) {
+	var set Set
+	if s.memory != nil {
+		set = *s.memory
+		set.Profiles = slices.Clone(set.Profiles)
+		return set, nil
+	}
+	if s.encrypted && s.key == nil {
+		return set, ErrLocked
+	}
+	var data []byte
+	var err error
+	if s.encrypted {
+		data, err = vault.ReadFile(s.path)
+	} else {
+		data, err = os.ReadFile(s.path)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return set, nil
+	}
+	if err != nil {
+		return set, err
+	}
+	if s.encrypted {
+		if data, err = s.key.Open(data); err != nil {
+			return set, err
+		}
+		defer clear(data)
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return Set{}, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return set, nil
+}
+
+// save keeps the profiles in memory or seals them into the vault file;
+// tokens are never written unencrypted.
+func (s *Store) save(set Set) error {
+	switch {
+	case s.memory != nil:
+		set.Profiles = slices.Clone(set.Profiles)
+		*s.memory = set
+		return nil
+	case !s.encrypted:
+		return ErrReadOnly
+	case s.key == nil:
+		return ErrLocked
+	}
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	sealed, err := s.key.Seal(data)
+	clear(data)
+	if err != nil {
+		return err
+	}
+	return vault.WriteFile(s.path, sealed)
+}
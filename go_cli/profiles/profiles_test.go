@@ -0,0 +1,239 @@
+package profiles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/vault"
+)
+
+const (
+	tokenA = "hf_aaaaaaaaaaaaaaaaaaaaaaaa"
+	tokenB = "hf_bbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func names(s Set) string {
+	var out []string
+	for _, p := range s.Profiles {
+		out = append(out, p.Name)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         func(s *Set) error
+		wantErr    bool
+		errIs      error // the error wrapped, if any
+		wantNames  string
+		wantActive string
+	}{
+		{"add", func(s *Set) error { return s.Add(" work ", tokenB) }, false, nil, "personal,org,work", "personal"},
+		{"add existing", func(s *Set) error { return s.Add("org", tokenB) }, true, ErrExists, "personal,org", "personal"},
+		{"add invalid", func(s *Set) error { return s.Add("two words", tokenB) }, true, nil, "personal,org", "personal"},
+		{"rename active", func(s *Set) error { return s.Rename("personal", "me") }, false, nil, "me,org", "me"},
+		{"rename other", func(s *Set) error { return s.Rename("org", "team") }, false, nil, "personal,team", "personal"},
+		{"rename to itself", func(s *Set) error { return s.Rename("org", "org") }, false, nil, "personal,org", "personal"},
+		{"rename to taken", func(s *Set) error { return s.Rename("org", "personal") }, true, ErrExists, "personal,org", "personal"},
+		{"rename missing", func(s *Set) error { return s.Rename("nobody", "x") }, true, ErrNotFound, "personal,org", "personal"},
+		{"delete other", func(s *Set) error { return s.Delete("org") }, false, nil, "personal", "personal"},
+		{"delete active", func(s *Set) error { return s.Delete("personal") }, false, nil, "org", ""},
+		{"delete missing", func(s *Set) error { return s.Delete("nobody") }, true, ErrNotFound, "personal,org", "personal"},
+		{"set active", func(s *Set) error { return s.SetActive("org") }, false, nil, "personal,org", "org"},
+		{"set active missing", func(s *Set) error { return s.SetActive("nobody") }, true, ErrNotFound, "personal,org", "personal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Set
+			if err := s.Add("personal", tokenA); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Add("org", tokenB); err != nil {
+				t.Fatal(err)
+			}
+			err := tt.op(&s)
+			if (err != nil) != tt.wantErr || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+				t.Errorf("error = %v, want error %v (%v)", err, tt.wantErr, tt.errIs)
+			}
+			if got := names(s); got != tt.wantNames {
+				t.Errorf("profiles = %s, want %s", got, tt.wantNames)
+			}
+			if s.Active != tt.wantActive {
+				t.Errorf("active = %q, want %q", s.Active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestSetFirstProfileActive(t *testing.T) {
+	var s Set
+	if _, ok := s.ActiveProfile(); ok {
+		t.Error("empty set has an active profile")
+	}
+	if err := s.Add("personal", tokenA); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := s.ActiveProfile(); !ok || p.Token != tokenA || p.AddedAt.IsZero() {
+		t.Errorf("ActiveProfile() = %+v, %v", p, ok)
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	var s Set
+	for _, name := range []string{"default", "default-2", "other"} {
+		if err := s.Add(name, tokenA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := map[string]string{"default": "default-3", "other": "other-2", "new": "new"}
+	for base, want := range tests {
+		if got := s.UniqueName(base); got != want {
+			t.Errorf("UniqueName(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"work", true},
+		{"org-token_2", true},
+		{strings.Repeat("x", maxNameLen), true},
+		{"", false},
+		{strings.Repeat("x", maxNameLen+1), false},
+		{"two words", false},
+		{"tab\tname", false},
+		{"new\nline", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("ValidateName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	var seed Set
+	if err := seed.Add("personal", tokenA); err != nil {
+		t.Fatal(err)
+	}
+	s := Memory(seed)
+	if s.Encrypted() || s.Locked() || s.Path() != "" {
+		t.Errorf("memory store: encrypted %v, locked %v, path %q", s.Encrypted(), s.Locked(), s.Path())
+	}
+	if _, err := s.Update(func(set *Set) error { return set.Add("org", tokenB) }); err != nil {
+		t.Fatal(err)
+	}
+	// A failed update leaves the stored profiles alone, even though fn
+	// changed its copy before failing.
+	if _, err := s.Update(func(set *Set) error {
+		set.Delete("personal")
+		return errors.New("abort")
+	}); err == nil {
+		t.Error("Update() dropped fn's error")
+	}
+	set, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(set); got != "personal,org" {
+		t.Errorf("profiles = %s, want personal,org", got)
+	}
+	if len(seed.Profiles) != 1 {
+		t.Errorf("seed set changed to %s", names(seed))
+	}
+}
+
+func TestPlainStoreReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.json")
+	if set, err := Open(path).Load(); err != nil || len(set.Profiles) != 0 {
+		t.Errorf("Load() of a missing file = %+v, %v", set, err)
+	}
+	data := `{"active": "personal", "profiles": [{"name": "personal", "token": "` + tokenA + `"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := Open(path)
+	set, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := set.ActiveProfile(); !ok || p.Token != tokenA {
+		t.Errorf("ActiveProfile() = %+v, %v", p, ok)
+	}
+	if _, err := s.Update(func(set *Set) error { return set.Add("org", tokenB) }); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Update() error = %v, want ErrReadOnly", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != data {
+		t.Errorf("plain file rewritten: %s", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("files after a refused save: %v", entries)
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "profiles.vault")
+	s := OpenVault(path)
+	if !s.Encrypted() || !s.Locked() {
+		t.Fatalf("new vault store: encrypted %v, locked %v", s.Encrypted(), s.Locked())
+	}
+	if _, err := s.Load(); !errors.Is(err, ErrLocked) {
+		t.Errorf("Load() while locked = %v, want ErrLocked", err)
+	}
+	if _, err := s.Update(func(*Set) error { return nil }); !errors.Is(err, ErrLocked) {
+		t.Errorf("Update() while locked = %v, want ErrLocked", err)
+	}
+	if vault.Exists(path) {
+		t.Fatal("locked store wrote the vault")
+	}
+
+	passphrase := []byte("correct horse")
+	if err := s.Unlock(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(func(set *Set) error { return set.Add("personal", tokenA) }); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("vault mode = %s, want 0600", fi.Mode().Perm())
+	}
+	if raw, _ := os.ReadFile(path); strings.Contains(string(raw), tokenA) {
+		t.Error("vault file contains the token in plaintext")
+	}
+
+	s.Lock()
+	if _, err := s.Load(); !errors.Is(err, ErrLocked) {
+		t.Errorf("Load() after Lock() = %v, want ErrLocked", err)
+	}
+
+	// A fresh store needs the right passphrase to read the profiles back.
+	again := OpenVault(path)
+	if err := again.Unlock([]byte("wrong")); !errors.Is(err, vault.ErrWrongPassphrase) {
+		t.Errorf("Unlock(wrong) = %v, want ErrWrongPassphrase", err)
+	}
+	if !again.Locked() {
+		t.Error("store unlocked by a wrong passphrase")
+	}
+	if err := again.Unlock(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	set, err := again.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := set.ActiveProfile(); !ok || p.Name != "personal" || p.Token != tokenA {
+		t.Errorf("ActiveProfile() = %+v, %v", p, ok)
+	}
+}
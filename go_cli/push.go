@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/audit"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hub"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+const progressBarWidth = 30
+
+// --- Push Command ---
+func runPush(args []string) error {
+	fset := flag.NewFlagSet("push", flag.ContinueOnError)
+	repo := fset.String("repo", "", "target repository as org/name")
+	private := fset.Bool("private", false, "create the repository as private")
+	message := fset.String("m", "", "commit message")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 || *repo == "" {
+		return errors.New("usage: nexa push --repo org/name [--private] [-m message] <run>")
+	}
+	dir := resolvePushDir(fset.Arg(0))
+	if token, err := hubToken(); err == nil {
+		if warning := pushTokenWarning(token); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
+	url, err := publishRun(context.Background(), dir, *repo, *private, *message, printProgress)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return err
+	}
+	fmt.Println("Published to", url)
+	return nil
+}
+
+// pushTokenWarning warns when the hub reports that token cannot push. A
+// token the hub could not be asked about gets no warning; the push itself
+// reports the failure.
+func pushTokenWarning(token string) string {
+	id, err := whoAmI(token)
+	if err != nil || id.CanWrite() {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %s's token has %s access; pushing needs a write token", id.Name, id.Scope())
+}
+
+// resolvePushDir accepts a job ID from the history as well as anything
+// resolveRunDir understands.
+func resolvePushDir(run string) string {
+	if job, err := jobHistory.Get(run); err == nil {
+		return filepath.Join(outputRoot, job.Output)
+	}
+	return resolveRunDir(run)
+}
+
+// publishRun creates repo if needed and uploads dir to it using the token
+// held by the session server.
+func publishRun(ctx context.Context, dir, repo string, private bool, message string, progress func(hub.Progress)) (string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return "", err
+	}
+	if _, _, err := hub.SplitRepoID(repo); err != nil {
+		return "", err
+	}
+	token, err := hubToken()
+	if err != nil {
+		return "", err
+	}
+	if message == "" {
+		message = "Upload " + filepath.Base(dir) + " with Nexa Auto"
+	}
+	client := hub.NewClient(appConfig.HubURL, token)
+	client.ResumeDir = filepath.Join(xdg.StateHome(), "uploads")
+	if err := client.CreateRepo(ctx, repo, private); err != nil {
+		return "", err
+	}
+	logEvent(eventlog.Info, eventlog.TypePublish, "Publishing run", eventlog.Fields{"dir": dir, "repo": client.RepoURL(repo)})
+	url, err := client.UploadDir(ctx, repo, dir, message, progress)
+	details := map[string]string{"dir": dir, "hub": appConfig.HubURL, "private": strconv.FormatBool(private)}
+	if err != nil {
+		details["error"] = err.Error()
+	} else {
+		details["url"] = url
+	}
+	recordAudit(audit.Push, repo, details)
+	return url, err
+}
+
+// hubToken returns the token stored in the session server, falling back
+// to HF_TOKEN.
+func hubToken() (string, error) {
+	t, err := sessionClient().GetToken()
+	if err == nil && t.Token != "" {
+		redact.AddSecret(t.Token)
+		return t.Token, nil
+	}
+	if env := os.Getenv("HF_TOKEN"); env != "" {
+		return env, nil
+	}
+	return "", fmt.Errorf("no Hugging Face token available (session server: %v)", err)
+}
+
+func printProgress(p hub.Progress) {
+	fmt.Fprintf(os.Stderr, "\r[%d/%d] %-40s %s", p.FileIndex, p.FileCount, truncate(p.File, 40), progressBar(p))
+	if p.Skipped || p.Sent == p.Size {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func progressBar(p hub.Progress) string {
+	if p.Skipped {
+		return "already on hub"
+	}
+	frac := 1.0
+	if p.Size > 0 {
+		frac = float64(p.Sent) / float64(p.Size)
+	}
+	filled := int(frac * progressBarWidth)
+	return fmt.Sprintf("[%s%s] %3.0f%% %s", strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), frac*100, formatBytes(p.Size))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "…" + s[len(s)-n+1:]
+}
+
+// --- Publish (TUI) ---
+type publishProgressMsg struct {
+	progress hub.Progress
+	ch       <-chan tea.Msg
+}
+
+type publishDoneMsg struct {
+	url string
+	err error
+}
+
+// startPublish uploads in the background and streams progress messages.
+func startPublish(dir, repo string) tea.Cmd {
+	ch := make(chan tea.Msg, 16)
+	go func() {
+		defer close(ch)
+		url, err := publishRun(context.Background(), dir, repo, false, "", func(p hub.Progress) {
+			select {
+			case ch <- publishProgressMsg{progress: p, ch: ch}:
+			default: // drop intermediate updates rather than stall the upload
+			}
+		})
+		ch <- publishDoneMsg{url: url, err: err}
+	}()
+	return waitPublish(ch)
+}
+
+func waitPublish(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (m model) publishView() string {
+	out := headerStyle.Render("Publish: "+m.publishJob.Output) + "\n\n"
+	out += "Hub:  " + appConfig.HubURL + "\n"
+	if m.tokenIdentity != nil {
+		out += "As:   " + m.tokenIdentity.String() + "\n"
+	}
+	out += "Repo: " + m.publishRepo
+	if !m.publishing && m.publishStatus == "" {
+		out += "█"
+	}
+	out += "\n\n"
+	if m.publishing {
+		p := m.publishProgress
+		out += fmt.Sprintf("[%d/%d] %s\n%s\n", p.FileIndex, p.FileCount, p.File, progressBar(p))
+	}
+	if m.publishStatus != "" {
+		out += m.publishStatus + "\n"
+	}
+	if w := m.writeTokenWarning(); w != "" && !m.publishing {
+		out += w + "\n"
+	}
+	if m.publishing {
+		return out + "\nUploading..."
+	}
+	return out + "\n[Enter] Publish  [ESC] Back"
+}
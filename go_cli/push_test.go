@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPushTokenWarning(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{
+			name:   "write token",
+			status: http.StatusOK,
+			body:   `{"name": "ada", "auth": {"accessToken": {"role": "write"}}}`,
+		},
+		{
+			name:   "read token",
+			status: http.StatusOK,
+			body:   `{"name": "ada", "auth": {"accessToken": {"role": "read"}}}`,
+			want:   "Warning: ada's token has read access; pushing needs a write token",
+		},
+		{
+			name:   "fine-grained read-only token",
+			status: http.StatusOK,
+			body: `{"name": "ada", "auth": {"accessToken": {"role": "fineGrained",
+				"fineGrained": {"scoped": [{"permissions": ["repo.content.read"]}]}}}}`,
+			want: "Warning: ada's token has fine-grained (read) access; pushing needs a write token",
+		},
+		{
+			name:   "rejected token",
+			status: http.StatusUnauthorized,
+			body:   `{"error": "Invalid credentials"}`,
+		},
+	}
+	hubURL := appConfig.HubURL
+	defer func() { appConfig.HubURL = hubURL }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			appConfig.HubURL = srv.URL
+			if got := pushTokenWarning("hf_test"); got != tt.want {
+				t.Errorf("pushTokenWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package redact masks secrets before text reaches Tune.log, the terminal
+// or an exported file. It recognizes Hugging Face tokens and bearer
+// credentials by shape, and any value registered with AddSecret verbatim.
+package redact
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted secret.
+const Mask = "[REDACTED]"
+
+// minSecretLen guards against registering values so short that masking
+// them would garble ordinary text.
+const minSecretLen = 6
+
+var (
+	hfToken = regexp.MustCompile(`\bhf_[A-Za-z0-9]{8,}`)
+	bearer  = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	// authHeader covers "Authorization: Basic ..." and similar schemes.
+	authHeader = regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?)(basic|token)\s+[A-Za-z0-9._~+/=-]+`)
+)
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// AddSecret registers values that must never be shown, such as the token
+// currently in use or secrets listed in the config. Empty and very short
+// values are ignored.
+func AddSecret(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLen {
+			continue
+		}
+		dup := false
+		for _, s := range secrets {
+			dup = dup || s == v
+		}
+		if !dup {
+			secrets = append(secrets, v)
+		}
+	}
+	// Longest first, so a secret containing another is masked whole.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// Reset forgets every registered secret.
+func Reset() {
+	mu.Lock()
+	secrets = nil
+	mu.Unlock()
+}
+
+// String returns s with every recognized secret replaced by Mask.
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+	s = authHeader.ReplaceAllString(s, "${1}${2} "+Mask)
+	s = bearer.ReplaceAllString(s, "${1}"+Mask)
+	return hfToken.ReplaceAllString(s, Mask)
+}
+
+// Bytes is String for byte slices.
+func Bytes(b []byte) []byte {
+	return []byte(String(string(b)))
+}
+
+// Value redacts strings inside v, descending into the maps and slices
+// produced by encoding/json. Other values are returned unchanged.
+func Value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return String(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			out[k] = Value(x)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = Value(x)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, x := range v {
+			out[i] = String(x)
+		}
+		return out
+	}
+	return v
+}
+
+// Token describes a token without revealing any of it, e.g. for status
+// lines: "hf_… (37 chars)".
+func Token(token string) string {
+	if token == "" {
+		return "(none)"
+	}
+	prefix := ""
+	if strings.HasPrefix(token, "hf_") {
+		prefix = "hf_"
+	}
+	return prefix + "… (" + strconv.Itoa(len(token)) + " chars)"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/report"
+)
+
+// reportFormats maps report formats to their file extensions.
+var reportFormats = map[string]string{"md": ".md", "html": ".html", "json": ".json"}
+
+// --- Report Command ---
+func runReport(args []string) error {
+	fset := flag.NewFlagSet("report", flag.ContinueOnError)
+	format := fset.String("format", "md", "report format: md, html or json")
+	out := fset.String("o", "", "write the report to this file instead of stdout")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 {
+		return errors.New("usage: nexa report [--format md|html|json] [-o file] <job>")
+	}
+	if _, ok := reportFormats[*format]; !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	job, err := jobHistory.Get(fset.Arg(0))
+	if err != nil {
+		return err
+	}
+	r, err := buildReport(job)
+	if err != nil {
+		return err
+	}
+	data, err := renderReport(r, *format)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+func buildReport(job history.Job) (*report.Report, error) {
+	logText, err := fetchJobLogs(job)
+	if err != nil {
+		logText = "(job log unavailable: " + err.Error() + ")"
+	}
+	return report.Build(job, filepath.Join(outputRoot, job.Output), logText)
+}
+
+// renderReport renders r in format with secrets masked, since reports
+// embed log excerpts and are meant to be shared. The fields are masked
+// before rendering so escaping cannot hide a secret from the redactor.
+func renderReport(r *report.Report, format string) ([]byte, error) {
+	r = redactReport(r)
+	switch format {
+	case "html":
+		s, err := r.HTML()
+		return []byte(s), err
+	case "json":
+		return r.JSON()
+	}
+	return []byte(r.Markdown()), nil
+}
+
+// redactReport returns a copy of r with secrets masked in every text field.
+func redactReport(r *report.Report) *report.Report {
+	out := *r
+	j := &out.Job
+	for _, s := range []*string{&j.ID, &j.Model, &j.Dataset, &j.Output, &j.Status, &j.LogPath, &j.Profile, &out.OutputDir} {
+		*s = redact.String(*s)
+	}
+	if r.Hardware != nil {
+		hw := *r.Hardware
+		hw.Hostname, hw.CPUModel = redact.String(hw.Hostname), redact.String(hw.CPUModel)
+		hw.GPUs = slices.Clone(hw.GPUs)
+		for i := range hw.GPUs {
+			hw.GPUs[i].Name = redact.String(hw.GPUs[i].Name)
+		}
+		out.Hardware, j.Hardware = &hw, &hw
+	}
+	out.Timeline = slices.Clone(r.Timeline)
+	for i := range out.Timeline {
+		out.Timeline[i].Label = redact.String(out.Timeline[i].Label)
+	}
+	out.LogExcerpt = make([]string, len(r.LogExcerpt))
+	for i, l := range r.LogExcerpt {
+		out.LogExcerpt[i] = redact.String(l)
+	}
+	out.Artifacts = slices.Clone(r.Artifacts)
+	for i := range out.Artifacts {
+		out.Artifacts[i].Path = redact.String(out.Artifacts[i].Path)
+	}
+	return &out
+}
+
+// exportReports writes the report for job in every format next to the
+// run's artifacts and returns a status line for the TUI.
+func exportReports(job history.Job) string {
+	r, err := buildReport(job)
+	if err != nil {
+		return "Report failed: " + err.Error()
+	}
+	dir := filepath.Join(outputRoot, job.Output)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "Report failed: " + err.Error()
+	}
+	for _, format := range []string{"md", "html", "json"} {
+		data, err := renderReport(r, format)
+		if err != nil {
+			return "Report failed: " + err.Error()
+		}
+		if err := os.WriteFile(filepath.Join(dir, "report"+reportFormats[format]), data, 0o644); err != nil {
+			return "Report failed: " + err.Error()
+		}
+	}
+	return fmt.Sprintf("Reports written to %s/report.{md,html,json}", dir)
+}
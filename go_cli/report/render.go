@@ -0,0 +1,236 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+// Markdown renders the report as GitHub-flavoured Markdown. The loss curve
+// is drawn as a text sparkline.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	j := r.Job
+	fmt.Fprintf(&b, "# Fine-tune report: %s\n\n", j.Output)
+	fmt.Fprintf(&b, "_Generated %s_\n\n", r.GeneratedAt.Format(time.RFC1123))
+
+	b.WriteString("## Request\n\n| Parameter | Value |\n|---|---|\n")
+	for _, row := range r.parameters() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], mdEscape(row[1]))
+	}
+
+	b.WriteString("\n## Hardware\n\n")
+	if hw := r.Hardware; hw != nil {
+		fmt.Fprintf(&b, "- Host: %s (%s/%s)\n", hw.Hostname, hw.OS, hw.Arch)
+		fmt.Fprintf(&b, "- CPU: %s, %d cores\n", orDash(hw.CPUModel), hw.CPUCores)
+		if hw.MemoryTotalMB > 0 {
+			fmt.Fprintf(&b, "- Memory: %d MB\n", hw.MemoryTotalMB)
+		}
+		for _, g := range hw.GPUs {
+			fmt.Fprintf(&b, "- GPU: %s, %d MB (driver %s)\n", g.Name, g.MemoryMB, g.Driver)
+		}
+	} else {
+		b.WriteString("No hardware snapshot was recorded for this job.\n")
+	}
+
+	b.WriteString("\n## Timeline\n\n")
+	for _, e := range r.Timeline {
+		fmt.Fprintf(&b, "- `%s` %s\n", e.Time.Format("2006-01-02 15:04:05"), e.Label)
+	}
+
+	b.WriteString("\n## Metrics\n\n")
+	fmt.Fprintf(&b, "- Final loss: %s\n- Best loss: %s\n- Logged steps: %d\n", fmtLoss(r.FinalLoss), fmtLoss(r.BestLoss), len(r.Losses))
+	if len(r.Losses) > 0 {
+		fmt.Fprintf(&b, "\n```\nloss %s\n```\n", sparkline(r.Losses))
+	}
+
+	fence := codeFence(r.LogExcerpt)
+	b.WriteString("\n## Log excerpt\n\n" + fence + "\n")
+	for _, l := range r.LogExcerpt {
+		b.WriteString(l + "\n")
+	}
+	b.WriteString(fence + "\n")
+
+	fmt.Fprintf(&b, "\n## Artifacts\n\nOutput directory: `%s`\n\n| File | Size | SHA-256 |\n|---|---:|---|\n", r.OutputDir)
+	for _, a := range r.Artifacts {
+		fmt.Fprintf(&b, "| %s | %d | `%s` |\n", mdEscape(a.Path), a.Size, a.SHA256)
+	}
+	return b.String()
+}
+
+// HTML renders the report as a single HTML page with the loss curve as an
+// inline SVG, so it can be shared without any other files.
+func (r *Report) HTML() (string, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, map[string]any{
+		"R":          r,
+		"Parameters": r.parameters(),
+		"Chart":      template.HTML(lossSVG(r.Losses, 640, 240)),
+		"FinalLoss":  fmtLoss(r.FinalLoss),
+		"BestLoss":   fmtLoss(r.BestLoss),
+	})
+	return buf.String(), err
+}
+
+func (r *Report) parameters() [][2]string {
+	j := r.Job
+	return [][2]string{
+		{"Job ID", j.ID},
+		{"Status", j.Status},
+		{"Base model", j.Model},
+		{"Dataset", j.Dataset},
+		{"Output", j.Output},
+		{"Local", fmt.Sprint(j.Local)},
+		{"Token profile", orDash(j.Profile)},
+		{"Submitted", j.SubmittedAt.Format("2006-01-02 15:04:05")},
+		{"Duration", j.Duration().Round(time.Second).String()},
+	}
+}
+
+func fmtLoss(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", *v)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// codeFence returns a backtick fence longer than any backtick run in lines,
+// so no line can close the code block early.
+func codeFence(lines []string) string {
+	longest := 0
+	for _, l := range lines {
+		run := 0
+		for _, c := range l {
+			if c == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func mdEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// sparkline draws the loss curve with block characters, one per point.
+func sparkline(pts []trainerstate.Point) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		lo, hi = math.Min(lo, p.Loss), math.Max(hi, p.Loss)
+	}
+	runes := []rune(blocks)
+	var b strings.Builder
+	for _, p := range pts {
+		i := 0
+		if hi > lo {
+			i = int((p.Loss - lo) / (hi - lo) * float64(len(runes)-1))
+		}
+		b.WriteRune(runes[i])
+	}
+	return fmt.Sprintf("%s  (%.4f → %.4f)", b.String(), pts[0].Loss, pts[len(pts)-1].Loss)
+}
+
+// lossSVG plots loss against step as an SVG polyline.
+func lossSVG(pts []trainerstate.Point, width, height int) string {
+	if len(pts) == 0 {
+		return "<p>No loss data was logged for this run.</p>"
+	}
+	const pad = 40
+	maxStep := 1
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		maxStep = max(maxStep, p.Step)
+		lo, hi = math.Min(lo, p.Loss), math.Max(hi, p.Loss)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	w, h := float64(width-2*pad), float64(height-2*pad)
+	var coords []string
+	for _, p := range pts {
+		x := pad + float64(p.Step)/float64(maxStep)*w
+		y := pad + (hi-p.Loss)/(hi-lo)*h
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#888"/>`, pad, height-pad, width-pad, height-pad)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#888"/>`, pad, pad, pad, height-pad)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="11" text-anchor="end">%.3f</text>`, pad-4, pad+4, hi)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="11" text-anchor="end">%.3f</text>`, pad-4, height-pad, lo)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="11" text-anchor="end">step %d</text>`, width-pad, height-pad+16, maxStep)
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="#6C63FF" stroke-width="2" points="%s"/>`, strings.Join(coords, " "))
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Fine-tune report: {{.R.Job.Output}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2em auto; color: #232946; }
+h1 { color: #6C63FF; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+pre { background: #16161a; color: #eee; padding: 1em; overflow-x: auto; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Fine-tune report: {{.R.Job.Output}}</h1>
+<p><em>Generated {{.R.GeneratedAt.Format "2006-01-02 15:04:05"}}</em></p>
+
+<h2>Request</h2>
+<table>
+{{range .Parameters}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+<h2>Hardware</h2>
+{{with .R.Hardware}}<ul>
+<li>Host: {{.Hostname}} ({{.OS}}/{{.Arch}})</li>
+<li>CPU: {{.CPUModel}}, {{.CPUCores}} cores</li>
+{{if .MemoryTotalMB}}<li>Memory: {{.MemoryTotalMB}} MB</li>{{end}}
+{{range .GPUs}}<li>GPU: {{.Name}}, {{.MemoryMB}} MB (driver {{.Driver}})</li>
+{{end}}</ul>{{else}}<p>No hardware snapshot was recorded for this job.</p>{{end}}
+
+<h2>Timeline</h2>
+<ul>
+{{range .R.Timeline}}<li><code>{{.Time.Format "2006-01-02 15:04:05"}}</code> {{.Label}}</li>
+{{end}}</ul>
+
+<h2>Metrics</h2>
+<p>Final loss: {{.FinalLoss}} &middot; Best loss: {{.BestLoss}} &middot; Logged steps: {{len .R.Losses}}</p>
+{{.Chart}}
+
+<h2>Log excerpt</h2>
+<pre>{{range .R.LogExcerpt}}{{.}}
+{{end}}</pre>
+
+<h2>Artifacts</h2>
+<p>Output directory: <code>{{.R.OutputDir}}</code></p>
+<table>
+<tr><th>File</th><th>Size</th><th>SHA-256</th></tr>
+{{range .R.Artifacts}}<tr><td>{{.Path}}</td><td>{{.Size}}</td><td><code>{{.SHA256}}</code></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
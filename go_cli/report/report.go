@@ -0,0 +1,166 @@
+// Package report assembles a self-contained summary of a fine-tune job from
+// the job history, the trainer's log and state files, and the artifacts on
+// disk, and renders it as Markdown, HTML or JSON.
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hwinfo"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+const (
+	// excerptHead and excerptTail bound the log lines copied into a report.
+	excerptHead = 10
+	excerptTail = 20
+)
+
+// Event is one entry of the job timeline.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Label string    `json:"label"`
+}
+
+// Artifact is one file of the run's output directory.
+type Artifact struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Report is everything known about a job, ready for rendering.
+type Report struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Job         history.Job          `json:"job"`
+	Hardware    *hwinfo.Snapshot     `json:"hardware,omitempty"`
+	Timeline    []Event              `json:"timeline"`
+	LogExcerpt  []string             `json:"log_excerpt"`
+	Losses      []trainerstate.Point `json:"losses"`
+	FinalLoss   *float64             `json:"final_loss,omitempty"`
+	BestLoss    *float64             `json:"best_loss,omitempty"`
+	OutputDir   string               `json:"output_dir"`
+	Artifacts   []Artifact           `json:"artifacts"`
+}
+
+// Build assembles the report for job. runDir is the job's output directory
+// and logText the contents of its trainer log, which may be empty.
+func Build(job history.Job, runDir, logText string) (*Report, error) {
+	r := &Report{
+		GeneratedAt: time.Now(),
+		Job:         job,
+		Hardware:    job.Hardware,
+		OutputDir:   runDir,
+		LogExcerpt:  excerpt(logText),
+		Losses:      []trainerstate.Point{},
+	}
+	if state, err := trainerstate.Find(runDir); err == nil {
+		r.Losses = state.Losses()
+		if v, ok := state.FinalLoss(); ok {
+			r.FinalLoss = &v
+		}
+		if v, ok := state.BestLoss(); ok {
+			r.BestLoss = &v
+		}
+	}
+	arts, err := manifest(runDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	r.Artifacts = arts
+	r.Timeline = timeline(job, runDir)
+	return r, nil
+}
+
+// JSON renders the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// excerpt keeps the head and tail of a log plus every error line between.
+func excerpt(text string) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return []string{}
+	}
+	if len(lines) <= excerptHead+excerptTail {
+		return lines
+	}
+	out := append([]string{}, lines[:excerptHead]...)
+	skipped := 0
+	for _, l := range lines[excerptHead : len(lines)-excerptTail] {
+		if strings.Contains(l, "[ERROR]") {
+			out = append(out, l)
+		} else {
+			skipped++
+		}
+	}
+	out = append(out, "... ("+strconv.Itoa(skipped)+" lines omitted) ...")
+	return append(out, lines[len(lines)-excerptTail:]...)
+}
+
+// timeline orders the known points in a job's life.
+func timeline(job history.Job, runDir string) []Event {
+	events := []Event{{Time: job.SubmittedAt, Label: "Submitted to trainer"}}
+	checkpoints, _ := filepath.Glob(filepath.Join(runDir, "checkpoint-*"))
+	for _, c := range checkpoints {
+		if fi, err := os.Stat(c); err == nil {
+			events = append(events, Event{Time: fi.ModTime(), Label: "Saved " + filepath.Base(c)})
+		}
+	}
+	if !job.FinishedAt.IsZero() {
+		events = append(events, Event{Time: job.FinishedAt, Label: "Reached status " + job.Status})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+// manifest lists and hashes the files of runDir, skipping intermediate
+// checkpoints.
+func manifest(runDir string) ([]Artifact, error) {
+	arts := []Artifact{}
+	err := filepath.WalkDir(runDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != runDir && strings.HasPrefix(d.Name(), "checkpoint-") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		sum, size, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(runDir, p)
+		arts = append(arts, Artifact{Path: filepath.ToSlash(rel), Size: size, SHA256: sum})
+		return nil
+	})
+	return arts, err
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
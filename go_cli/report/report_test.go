@@ -0,0 +1,191 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/trainerstate"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sha(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+var t0 = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const stateJSON = `{"global_step": 30, "log_history": [{"step": 10, "loss": 2.0}, {"step": 20, "loss": 1.0},
+	{"step": 30, "loss": 1.5}, {"step": 30, "eval_loss": 1.2}]}`
+
+// buildReport builds the report of a finished job whose run directory has
+// weights, a config, a trainer state and one checkpoint.
+func buildReport(t *testing.T, job history.Job, logText string) *Report {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "adapter_model.safetensors"), "weights")
+	writeFile(t, filepath.Join(dir, "adapter_config.json"), "{}")
+	writeFile(t, filepath.Join(dir, trainerstate.FileName), stateJSON)
+	writeFile(t, filepath.Join(dir, "checkpoint-10", "optimizer.pt"), "skipped")
+	r, err := Build(job, dir, logText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func finishedJob() history.Job {
+	return history.Job{ID: "job-1", Model: "gpt2", Dataset: "org/data", Output: "run-a", Status: history.StatusFinished,
+		SubmittedAt: t0, FinishedAt: t0.Add(time.Hour)}
+}
+
+func TestBuild(t *testing.T) {
+	r := buildReport(t, finishedJob(), "one\ntwo\n")
+
+	wantArts := []Artifact{
+		{Path: "adapter_config.json", Size: 2, SHA256: sha("{}")},
+		{Path: "adapter_model.safetensors", Size: 7, SHA256: sha("weights")},
+		{Path: trainerstate.FileName, Size: int64(len(stateJSON)), SHA256: sha(stateJSON)},
+	}
+	if !reflect.DeepEqual(r.Artifacts, wantArts) {
+		t.Errorf("manifest = %+v, want %+v", r.Artifacts, wantArts)
+	}
+	if len(r.Losses) != 3 || *r.FinalLoss != 1.5 || *r.BestLoss != 1.0 {
+		t.Errorf("losses = %+v, final %v, best %v", r.Losses, *r.FinalLoss, *r.BestLoss)
+	}
+	var labels []string
+	for _, e := range r.Timeline {
+		labels = append(labels, e.Label)
+	}
+	want := []string{"Submitted to trainer", "Reached status finished", "Saved checkpoint-10"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("timeline = %v, want %v", labels, want)
+	}
+	if !reflect.DeepEqual(r.LogExcerpt, []string{"one", "two"}) {
+		t.Errorf("excerpt = %q", r.LogExcerpt)
+	}
+
+	// A job whose run directory is gone still gets a report.
+	r, err := Build(finishedJob(), filepath.Join(t.TempDir(), "missing"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Artifacts) != 0 || len(r.Losses) != 0 || r.FinalLoss != nil || len(r.LogExcerpt) != 0 {
+		t.Errorf("report of a missing run = %+v", r)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	var lines []string
+	for i := range 50 {
+		line := fmt.Sprintf("line %d", i)
+		if i == 25 {
+			line = "[ERROR] out of memory"
+		}
+		lines = append(lines, line)
+	}
+	got := excerpt(strings.Join(lines, "\n") + "\n")
+	want := append(append(append([]string{}, lines[:excerptHead]...),
+		"[ERROR] out of memory", "... (19 lines omitted) ..."), lines[50-excerptTail:]...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("excerpt =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	job := finishedJob()
+	job.Dataset = "a|b"
+	r := buildReport(t, job, "plain\n```python\nprint('x')\n````\n")
+	md := r.Markdown()
+
+	// The fence outlasts the longest backtick run in the excerpt.
+	want := "## Log excerpt\n\n`````\nplain\n```python\nprint('x')\n````\n`````\n"
+	if !strings.Contains(md, want) {
+		t.Errorf("log excerpt not fenced safely:\n%s", md)
+	}
+	for _, want := range []string{
+		"# Fine-tune report: run-a",
+		"| Dataset | a\\|b |",
+		"- Final loss: 1.5000\n- Best loss: 1.0000\n- Logged steps: 3",
+		"loss ",
+		"| adapter_model.safetensors | 7 | `" + sha("weights") + "` |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "optimizer.pt") {
+		t.Error("markdown lists a checkpoint file")
+	}
+	if got := codeFence([]string{"no backticks"}); got != "```" {
+		t.Errorf("codeFence() = %q, want three backticks", got)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	job := finishedJob()
+	job.Output = `<script>alert("x")</script>`
+	r := buildReport(t, job, "loss <0.5 & falling\n")
+	page, err := r.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("HTML contains an unescaped job field")
+	}
+	for _, want := range []string{
+		"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;",
+		"loss &lt;0.5 &amp; falling",
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		"<polyline",
+		sha("weights"),
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML lacks %q", want)
+		}
+	}
+
+	empty, err := (&Report{Job: finishedJob(), Losses: []trainerstate.Point{}}).HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(empty, "<svg") || !strings.Contains(empty, "No loss data") {
+		t.Error("HTML of a run without losses draws a chart")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	r := buildReport(t, finishedJob(), "one\n")
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Job.ID != "job-1" || got.OutputDir != r.OutputDir || len(got.Losses) != 3 || *got.FinalLoss != 1.5 {
+		t.Errorf("decoded report = %+v", got)
+	}
+	if !reflect.DeepEqual(got.Artifacts, r.Artifacts) {
+		t.Errorf("decoded manifest = %+v, want %+v", got.Artifacts, r.Artifacts)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/history"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/report"
+)
+
+func TestRenderReportRedactsBeforeEscaping(t *testing.T) {
+	// Escaping would turn this secret into something the redactor no
+	// longer recognizes.
+	const secret = `s3<cr"et&pw'`
+	redact.AddSecret(secret)
+	t.Cleanup(redact.Reset)
+
+	r, err := report.Build(history.Job{
+		ID: "job-1", Model: "gpt2", Dataset: "data-" + secret, Output: "run-a",
+		Status: history.StatusFinished, SubmittedAt: time.Now(),
+	}, t.TempDir(), "loading\nlogin with password "+secret+"\ndone\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted, _ := json.Marshal(secret)
+	forms := []string{secret, template.HTMLEscapeString(secret), strings.Trim(string(quoted), `"`)}
+	for _, format := range []string{"md", "html", "json"} {
+		data, err := renderReport(r, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, form := range forms {
+			if strings.Contains(string(data), form) {
+				t.Errorf("%s report contains the secret as %q", format, form)
+			}
+		}
+		if !strings.Contains(string(data), redact.Mask) {
+			t.Errorf("%s report has no mask", format)
+		}
+	}
+	if !strings.Contains(r.Job.Dataset, secret) {
+		t.Error("rendering changed the report itself")
+	}
+}
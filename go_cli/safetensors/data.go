@@ -0,0 +1,87 @@
+package safetensors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// ReadValues decodes the data of t as float64 values. r must be the file
+// the header was parsed from.
+func ReadValues(r io.ReaderAt, h *Header, t Tensor) ([]float64, error) {
+	elemSize, ok := dtypeSizes[t.DType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown dtype %q", ErrCorrupt, t.DType)
+	}
+	buf := make([]byte, t.Size())
+	if _, err := r.ReadAt(buf, h.DataOffset()+t.Begin); err != nil {
+		return nil, fmt.Errorf("tensor %q: %w", t.Name, err)
+	}
+	out := make([]float64, len(buf)/int(elemSize))
+	le := binary.LittleEndian
+	for i := range out {
+		b := buf[i*int(elemSize):]
+		switch t.DType {
+		case "F64":
+			out[i] = math.Float64frombits(le.Uint64(b))
+		case "F32":
+			out[i] = float64(math.Float32frombits(le.Uint32(b)))
+		case "BF16":
+			out[i] = float64(math.Float32frombits(uint32(le.Uint16(b)) << 16))
+		case "F16":
+			out[i] = halfToFloat(le.Uint16(b))
+		case "I64":
+			out[i] = float64(int64(le.Uint64(b)))
+		case "U64":
+			out[i] = float64(le.Uint64(b))
+		case "I32":
+			out[i] = float64(int32(le.Uint32(b)))
+		case "U32":
+			out[i] = float64(le.Uint32(b))
+		case "I16":
+			out[i] = float64(int16(le.Uint16(b)))
+		case "U16":
+			out[i] = float64(le.Uint16(b))
+		case "I8":
+			out[i] = float64(int8(b[0]))
+		case "U8", "BOOL":
+			out[i] = float64(b[0])
+		default:
+			return nil, fmt.Errorf("tensor %q: reading %s values is not supported", t.Name, t.DType)
+		}
+	}
+	return out, nil
+}
+
+// ReadFileValues is a convenience wrapper around ReadValues that opens the
+// file the header was read from.
+func ReadFileValues(h *Header, t Tensor) ([]float64, error) {
+	f, err := os.Open(h.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadValues(f, h, t)
+}
+
+// halfToFloat converts an IEEE 754 binary16 value to float64.
+func halfToFloat(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(1+frac/1024, exp-15)
+}
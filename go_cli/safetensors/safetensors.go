@@ -0,0 +1,239 @@
+// Package safetensors reads the header of .safetensors files produced by
+// the trainer backend without loading any tensor data.
+package safetensors
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// maxHeaderSize guards against absurd header lengths in corrupt files.
+const maxHeaderSize = 100 << 20
+
+var (
+	// ErrTruncated is returned when the file is shorter than its header claims.
+	ErrTruncated = errors.New("safetensors: file truncated")
+	// ErrCorrupt is returned when the header is malformed or inconsistent.
+	ErrCorrupt = errors.New("safetensors: corrupt header")
+)
+
+// dtypeSizes maps safetensors dtype names to their size in bytes.
+var dtypeSizes = map[string]int64{
+	"BOOL":    1,
+	"U8":      1,
+	"I8":      1,
+	"F8_E4M3": 1,
+	"F8_E5M2": 1,
+	"I16":     2,
+	"U16":     2,
+	"F16":     2,
+	"BF16":    2,
+	"I32":     4,
+	"U32":     4,
+	"F32":     4,
+	"I64":     8,
+	"U64":     8,
+	"F64":     8,
+}
+
+// Tensor describes a single entry of a safetensors header.
+type Tensor struct {
+	Name  string  `json:"name"`
+	DType string  `json:"dtype"`
+	Shape []int64 `json:"shape"`
+	Begin int64   `json:"begin"` // offset into the data section
+	End   int64   `json:"end"`
+}
+
+// NumElements returns the number of elements in the tensor. Parse has
+// checked that the shape is valid and its product fits in an int64.
+func (t Tensor) NumElements() int64 {
+	n := int64(1)
+	for _, d := range t.Shape {
+		n *= d
+	}
+	return n
+}
+
+// Size returns the size of the tensor data in bytes.
+func (t Tensor) Size() int64 {
+	return t.End - t.Begin
+}
+
+// Header is the parsed header of a safetensors file.
+type Header struct {
+	Path       string            `json:"path,omitempty"`
+	FileSize   int64             `json:"file_size"`
+	HeaderSize int64             `json:"header_size"`
+	Tensors    []Tensor          `json:"tensors"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// DataOffset returns the absolute file offset where tensor data begins.
+func (h *Header) DataOffset() int64 {
+	return 8 + h.HeaderSize
+}
+
+// ParamCount returns the total number of elements across all tensors.
+func (h *Header) ParamCount() int64 {
+	var n int64
+	for _, t := range h.Tensors {
+		n += t.NumElements()
+	}
+	return n
+}
+
+// DataSize returns the total number of tensor bytes described by the header.
+func (h *Header) DataSize() int64 {
+	var n int64
+	for _, t := range h.Tensors {
+		n += t.Size()
+	}
+	return n
+}
+
+// Tensor returns the tensor with the given name, if present.
+func (h *Header) Tensor(name string) (Tensor, bool) {
+	for _, t := range h.Tensors {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Tensor{}, false
+}
+
+// ReadHeader opens path and parses its safetensors header.
+func ReadHeader(path string) (*Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h, err := Parse(f, fi.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	h.Path = path
+	return h, nil
+}
+
+type rawEntry struct {
+	DType       string  `json:"dtype"`
+	Shape       []int64 `json:"shape"`
+	DataOffsets []int64 `json:"data_offsets"`
+}
+
+// Parse reads a safetensors header from r, validating every tensor's
+// offsets against size, the total length of the underlying file.
+func Parse(r io.ReaderAt, size int64) (*Header, error) {
+	if size < 8 {
+		return nil, fmt.Errorf("%w: %d bytes, need at least 8", ErrTruncated, size)
+	}
+	var lenBuf [8]byte
+	if _, err := r.ReadAt(lenBuf[:], 0); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(lenBuf[:])
+	if n > maxHeaderSize {
+		return nil, fmt.Errorf("%w: header length %d exceeds limit", ErrCorrupt, n)
+	}
+	if int64(n) > size-8 {
+		return nil, fmt.Errorf("%w: header length %d but only %d bytes follow", ErrTruncated, n, size-8)
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, 8); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	h := &Header{FileSize: size, HeaderSize: int64(n)}
+	if meta, ok := raw["__metadata__"]; ok {
+		if err := json.Unmarshal(meta, &h.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: __metadata__: %v", ErrCorrupt, err)
+		}
+		delete(raw, "__metadata__")
+	}
+
+	dataLen := size - h.DataOffset()
+	for name, msg := range raw {
+		var e rawEntry
+		if err := json.Unmarshal(msg, &e); err != nil {
+			return nil, fmt.Errorf("%w: tensor %q: %v", ErrCorrupt, name, err)
+		}
+		t := Tensor{Name: name, DType: e.DType, Shape: e.Shape}
+		if len(e.DataOffsets) != 2 {
+			return nil, fmt.Errorf("%w: tensor %q: expected 2 data offsets, got %d", ErrCorrupt, name, len(e.DataOffsets))
+		}
+		t.Begin, t.End = e.DataOffsets[0], e.DataOffsets[1]
+		if t.Begin < 0 || t.End < t.Begin {
+			return nil, fmt.Errorf("%w: tensor %q: invalid offsets [%d, %d]", ErrCorrupt, name, t.Begin, t.End)
+		}
+		if t.End > dataLen {
+			return nil, fmt.Errorf("%w: tensor %q ends at %d but data section is %d bytes", ErrTruncated, name, t.End, dataLen)
+		}
+		elemSize, ok := dtypeSizes[t.DType]
+		if !ok {
+			return nil, fmt.Errorf("%w: tensor %q: unknown dtype %q", ErrCorrupt, name, t.DType)
+		}
+		count, ok := elementCount(t.Shape)
+		if !ok || count > math.MaxInt64/elemSize {
+			return nil, fmt.Errorf("%w: tensor %q: invalid shape %v", ErrCorrupt, name, t.Shape)
+		}
+		if want := count * elemSize; want != t.Size() {
+			return nil, fmt.Errorf("%w: tensor %q: shape %v of %s needs %d bytes, offsets give %d", ErrCorrupt, name, t.Shape, t.DType, want, t.Size())
+		}
+		h.Tensors = append(h.Tensors, t)
+	}
+
+	// Tensors must not overlap in the data section.
+	sort.Slice(h.Tensors, func(i, j int) bool { return h.Tensors[i].Begin < h.Tensors[j].Begin })
+	for i := 1; i < len(h.Tensors); i++ {
+		prev, cur := h.Tensors[i-1], h.Tensors[i]
+		if cur.Begin < prev.End {
+			return nil, fmt.Errorf("%w: tensors %q and %q overlap", ErrCorrupt, prev.Name, cur.Name)
+		}
+	}
+	sort.Slice(h.Tensors, func(i, j int) bool { return h.Tensors[i].Name < h.Tensors[j].Name })
+	return h, nil
+}
+
+// elementCount multiplies out shape, reporting false for negative
+// dimensions or a product that overflows an int64.
+func elementCount(shape []int64) (int64, bool) {
+	for _, d := range shape {
+		if d < 0 {
+			return 0, false
+		}
+		if d == 0 {
+			return 0, true
+		}
+	}
+	n := int64(1)
+	for _, d := range shape {
+		if n > math.MaxInt64/d {
+			return 0, false
+		}
+		n *= d
+	}
+	return n, true
+}
+
+// DTypeSize returns the element size in bytes of a safetensors dtype.
+func DTypeSize(dtype string) (int64, bool) {
+	n, ok := dtypeSizes[dtype]
+	return n, ok
+}
@@ -0,0 +1,120 @@
+package safetensors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// build lays out a safetensors file: the header length, the header JSON
+// and data bytes of zeros.
+func build(header string, data int) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, uint64(len(header)))
+	b.WriteString(header)
+	b.Write(make([]byte, data))
+	return b.Bytes()
+}
+
+func parse(file []byte) (*Header, error) {
+	return Parse(bytes.NewReader(file), int64(len(file)))
+}
+
+func TestParse(t *testing.T) {
+	file := build(`{"__metadata__": {"format": "pt"},
+		"lora_B": {"dtype": "F16", "shape": [2, 4], "data_offsets": [24, 40]},
+		"lora_A": {"dtype": "F32", "shape": [3, 2], "data_offsets": [0, 24]},
+		"empty": {"dtype": "F32", "shape": [0, 8], "data_offsets": [40, 40]}}`, 40)
+	h, err := parse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Metadata["format"] != "pt" {
+		t.Errorf("Metadata = %v", h.Metadata)
+	}
+	if len(h.Tensors) != 3 || h.Tensors[0].Name != "empty" || h.Tensors[1].Name != "lora_A" {
+		t.Fatalf("Tensors = %+v, want them sorted by name", h.Tensors)
+	}
+	if got := h.ParamCount(); got != 14 {
+		t.Errorf("ParamCount() = %d, want 14", got)
+	}
+	if got := h.DataSize(); got != 40 {
+		t.Errorf("DataSize() = %d, want 40", got)
+	}
+	if a, _ := h.Tensor("lora_A"); a.NumElements() != 6 || a.Size() != 24 {
+		t.Errorf("lora_A = %+v", a)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tensor := func(entry string) []byte {
+		return build(`{"t": `+entry+`}`, 16)
+	}
+	tests := []struct {
+		name string
+		file []byte
+		want error
+	}{
+		{"shorter than the length prefix", []byte{1, 0, 0}, ErrTruncated},
+		{"header longer than the file", append(build(`{}`, 0)[:8:8], '{'), ErrTruncated},
+		{"absurd header length", binary.LittleEndian.AppendUint64(nil, math.MaxUint64), ErrCorrupt},
+		{"header is not JSON", build(`{"t": [`, 0), ErrCorrupt},
+		{"offsets past the end", tensor(`{"dtype": "F32", "shape": [8], "data_offsets": [0, 32]}`), ErrTruncated},
+		{"negative offset", tensor(`{"dtype": "U8", "shape": [4], "data_offsets": [-4, 0]}`), ErrCorrupt},
+		{"end before begin", tensor(`{"dtype": "U8", "shape": [0], "data_offsets": [8, 4]}`), ErrCorrupt},
+		{"one offset", tensor(`{"dtype": "U8", "shape": [4], "data_offsets": [4]}`), ErrCorrupt},
+		{"unknown dtype", tensor(`{"dtype": "F128", "shape": [1], "data_offsets": [0, 16]}`), ErrCorrupt},
+		{"shape does not match size", tensor(`{"dtype": "F32", "shape": [2, 3], "data_offsets": [0, 16]}`), ErrCorrupt},
+		{"negative dimensions", tensor(`{"dtype": "F32", "shape": [-2, -2], "data_offsets": [0, 16]}`), ErrCorrupt},
+		{"one negative dimension", tensor(`{"dtype": "U8", "shape": [-4], "data_offsets": [0, 0]}`), ErrCorrupt},
+		// 2^62 · 4 wraps to 0 and 2^32 · 2^32 to 0; without overflow
+		// checks both would match an empty tensor.
+		{"element count overflows", tensor(`{"dtype": "U8", "shape": [4294967296, 4294967296], "data_offsets": [0, 0]}`), ErrCorrupt},
+		{"byte size overflows", tensor(`{"dtype": "F32", "shape": [4611686018427387904], "data_offsets": [0, 0]}`), ErrCorrupt},
+		{"overlapping tensors", build(`{
+			"a": {"dtype": "F32", "shape": [2], "data_offsets": [0, 8]},
+			"b": {"dtype": "F32", "shape": [2], "data_offsets": [4, 12]}}`, 16), ErrCorrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parse(tt.file); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	file := build(`{"w": {"dtype": "F32", "shape": [3], "data_offsets": [0, 12]},
+		"h": {"dtype": "F16", "shape": [2], "data_offsets": [12, 16]}}`, 16)
+	h, err := parse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := file[h.DataOffset():]
+	for i, v := range []float32{1.5, -2, 0.25} {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	binary.LittleEndian.PutUint16(data[12:], 0x3c00) // 1.0
+	binary.LittleEndian.PutUint16(data[14:], 0xc000) // -2.0
+
+	tests := map[string][]float64{"w": {1.5, -2, 0.25}, "h": {1, -2}}
+	for name, want := range tests {
+		tensor, _ := h.Tensor(name)
+		got, err := ReadValues(bytes.NewReader(file), h, tensor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s = %v, want %v", name, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s = %v, want %v", name, got, want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package session talks to session_server.py, which keeps the Hugging Face
+// token encrypted in memory for the trainer backend.
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+)
+
+// Timeout bounds every request to the session server.
+const Timeout = 5 * time.Second
+
+// Client calls the session server over TCP or a Unix domain socket.
+type Client struct {
+	backend *backend.Client
+}
+
+// NewClient returns a client for the session server at endpoint, either a
+// base URL or unix:///path/to/socket.
+func NewClient(endpoint string) *Client {
+	return New(backend.NewOrInvalid(endpoint, Timeout))
+}
+
+// New returns a client using an existing backend client, e.g. one chosen
+// by backend.Failover.
+func New(c *backend.Client) *Client {
+	return &Client{backend: c}
+}
+
+// Endpoint returns the endpoint the client talks to.
+func (c *Client) Endpoint() string {
+	return c.backend.Endpoint
+}
+
+// Token is the token currently held by the session server.
+type Token struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// GetToken fetches the stored token.
+func (c *Client) GetToken() (Token, error) {
+	var t Token
+	resp, err := c.backend.Get(context.Background(), "/get_token")
+	if err != nil {
+		return t, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return t, statusError(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&t)
+	return t, err
+}
+
+// SetToken stores token in the session server and returns how long the
+// server will keep it.
+func (c *Client) SetToken(token string) (time.Duration, error) {
+	body, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.backend.Post(context.Background(), "/set_token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError(resp)
+	}
+	var stored struct {
+		ExpiresIn int `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+		return 0, err
+	}
+	return time.Duration(stored.ExpiresIn) * time.Second, nil
+}
+
+// ClearToken removes the stored token.
+func (c *Client) ClearToken() error {
+	resp, err := c.backend.Post(context.Background(), "/clear_token", "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	return nil
+}
+
+// statusError turns a FastAPI error response into an error.
+func statusError(resp *http.Response) error {
+	var body struct {
+		Detail string `json:"detail"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Detail != "" {
+		return fmt.Errorf("session server: %s (%d)", body.Detail, resp.StatusCode)
+	}
+	return fmt.Errorf("session server: unexpected status code %d", resp.StatusCode)
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -14,27 +13,27 @@ type SplashArt struct {
 	Background string   // Background color hex (e.g., "#101820")
 	Border     lipgloss.Border
 	Padding    [2]int // [vertical, horizontal]
-	Align      lipgloss.Alignment
+	Align      lipgloss.Position
 	Bold       bool
 }
 
 // colorNameToHex maps common color names to hex codes.
 var colorNameToHex = map[string]string{
-	"green":    "#39FF14",
-	"cyan":     "#00FFFF",
-	"magenta":  "#FF00FF",
-	"yellow":   "#FFFF00",
-	"red":      "#FF3131",
-	"blue":     "#00BFFF",
-	"white":    "#FFFFFF",
-	"black":    "#000000",
-	"orange":   "#FFA500",
-	"purple":   "#A020F0",
-	"gray":     "#888888",
-	"lime":     "#BFFF00",
-	"pink":     "#FF69B4",
-	"teal":     "#008080",
-	"gold":     "#FFD700",
+	"green":   "#39FF14",
+	"cyan":    "#00FFFF",
+	"magenta": "#FF00FF",
+	"yellow":  "#FFFF00",
+	"red":     "#FF3131",
+	"blue":    "#00BFFF",
+	"white":   "#FFFFFF",
+	"black":   "#000000",
+	"orange":  "#FFA500",
+	"purple":  "#A020F0",
+	"gray":    "#888888",
+	"lime":    "#BFFF00",
+	"pink":    "#FF69B4",
+	"teal":    "#008080",
+	"gold":    "#FFD700",
 }
 
 // Render generates the styled splash art as a string.
@@ -59,26 +58,20 @@ func (s SplashArt) Render() string {
 	return "\n" + box.Render(strings.Join(styledLines, "\n")) + "\n"
 }
 
-// Example usage: Generate a splash artifact for "NEXA"
-func main() {
-	nexaArt := []string{
+// nexaSplash is the banner shown above the TUI menus.
+var nexaSplash = SplashArt{
+	Art: []string{
 		"███╗   ██╗███████╗██╗  ██╗ █████╗      █████╗ ██╗   ██╗████████╗ ██████╗ ",
 		"████╗  ██║██╔════╝╚██╗██╔╝██╔══██╗    ██╔══██╗██║   ██║╚══██╔══╝██╔═══██╗",
 		"██╔██╗ ██║█████╗   ╚███╔╝ ███████║    ███████║██║   ██║   ██║   ██║   ██║",
 		"██║╚██╗██║██╔══╝   ██╔██╗ ██╔══██║    ██╔══██║██║   ██║   ██║   ██║   ██║",
 		"██║ ╚████║███████╗██╔╝ ██╗██║  ██║    ██║  ██║╚██████╔╝   ██║   ╚██████╔╝",
 		"╚═╝  ╚═══╝╚══════╝╚═╝  ╚═╝╚═╝  ╚═╝    ╚═╝  ╚═╝ ╚═════╝    ╚═╝    ╚═════╝ ",
-	}
-
-	splash := SplashArt{
-		Art:        nexaArt,
-		ColorName:  "green",
-		Background: "#101820",
-		Border:     lipgloss.ThickBorder(),
-		Padding:    [2]int{1, 4},
-		Align:      lipgloss.Center,
-		Bold:       true,
-	}
-
-	fmt.Print(splash.Render())
+	},
+	ColorName:  "green",
+	Background: "#101820",
+	Border:     lipgloss.ThickBorder(),
+	Padding:    [2]int{1, 4},
+	Align:      lipgloss.Center,
+	Bold:       true,
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/uptime"
+)
+
+// --- Monitor Status ---
+const (
+	// monitorRefresh is how often the main menu rereads the probe history.
+	monitorRefresh = 30 * time.Second
+	// monitorStale is how old the probe history may be before the monitor
+	// is assumed to have stopped.
+	monitorStale = 5 * time.Minute
+)
+
+// monitorStatusMsg carries the main menu's status line; empty when no
+// monitor has recorded any probes.
+type monitorStatusMsg string
+
+// loadMonitorStatus reads the probe history written by `nexa monitor`.
+func loadMonitorStatus() tea.Msg {
+	h, err := uptime.Load(appConfig.Monitor.HistoryPath)
+	if err != nil {
+		return monitorStatusMsg("")
+	}
+	return monitorStatusMsg(monitorStatusLine(h.Status(time.Now()), time.Now()))
+}
+
+func refreshMonitorStatus() tea.Cmd {
+	return tea.Tick(monitorRefresh, func(time.Time) tea.Msg { return loadMonitorStatus() })
+}
+
+// monitorStatusLine summarizes each backend in one line, e.g.
+// "trainer up 99.9% (24h) · session down 12m". A backend is up when any of
+// its endpoints is, and its uptime is that of its best endpoint.
+func monitorStatusLine(s uptime.Status, now time.Time) string {
+	type summary struct {
+		up     bool
+		since  time.Time
+		uptime float64
+		known  bool
+	}
+	var names []string
+	backends := map[string]*summary{}
+	for _, e := range s.Endpoints {
+		b, ok := backends[e.Backend]
+		if !ok {
+			b = &summary{}
+			backends[e.Backend] = b
+			names = append(names, e.Backend)
+		}
+		b.up = b.up || e.Up
+		if !e.Up && e.Since.After(b.since) {
+			b.since = e.Since
+		}
+		if pct, ok := e.Uptime["24h"]; ok && (!b.known || pct > b.uptime) {
+			b.uptime, b.known = pct, true
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		b := backends[name]
+		switch {
+		case !b.up:
+			parts = append(parts, fmt.Sprintf("%s down %s", name, shortDuration(now.Sub(b.since))))
+		case b.known:
+			parts = append(parts, fmt.Sprintf("%s up %.1f%% (24h)", name, b.uptime))
+		default:
+			parts = append(parts, name+" up")
+		}
+	}
+	if age := now.Sub(s.Updated); age > monitorStale {
+		parts = append(parts, "monitor last ran "+shortDuration(age)+" ago")
+	}
+	return "Backends: " + strings.Join(parts, " · ")
+}
+
+// shortDuration formats d to the largest whole unit, e.g. "12m" or "3h".
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(max(d, 0)/time.Second))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Token Input ---
+// tokenFormat matches Hugging Face user access tokens.
+var tokenFormat = regexp.MustCompile(`^hf_[A-Za-z0-9]{20,}$`)
+
+// newTokenInput returns a masked single-line field for entering a token.
+// Pasted text arrives through the same field, including bracketed paste.
+func newTokenInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "hf_…"
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.CharLimit = 256
+	ti.Width = 48
+	// A static cursor keeps the field independent of blink messages.
+	ti.Cursor.SetMode(cursor.CursorStatic)
+	return ti
+}
+
+// validateToken checks the basic shape of a token before it leaves the TUI.
+func validateToken(token string) error {
+	switch {
+	case token == "":
+		return errors.New("token is empty")
+	case !strings.HasPrefix(token, "hf_"):
+		return errors.New(`token must start with "hf_"`)
+	case !tokenFormat.MatchString(token):
+		return errors.New("token must be hf_ followed by at least 20 letters or digits")
+	}
+	return nil
+}
+
+// startTokenInput clears and focuses the token field.
+func (m *model) startTokenInput() tea.Cmd {
+	m.tokenInput.Reset()
+	m.tokenError = ""
+	return m.tokenInput.Focus()
+}
+
+// stopTokenInput discards whatever was typed.
+func (m *model) stopTokenInput() {
+	m.tokenInput.Reset()
+	m.tokenInput.Blur()
+	m.tokenError = ""
+}
+
+// updateTokenInput feeds a key to the focused token field. Enter trims and
+// validates the token, clears the field and hands the token to submit.
+func (m *model) updateTokenInput(msg tea.KeyMsg, submit func(string) tea.Cmd) tea.Cmd {
+	if msg.Type != tea.KeyEnter {
+		var cmd tea.Cmd
+		m.tokenInput, cmd = m.tokenInput.Update(msg)
+		m.tokenError = ""
+		return cmd
+	}
+	token := strings.TrimSpace(m.tokenInput.Value())
+	if err := validateToken(token); err != nil {
+		m.tokenError = err.Error()
+		return nil
+	}
+	m.stopTokenInput()
+	return submit(token)
+}
+
+// tokenInputView renders the field and any validation error.
+func (m model) tokenInputView() string {
+	out := m.tokenInput.View()
+	if m.tokenError != "" {
+		out += "\n" + errorStyle.Render(m.tokenError)
+	}
+	return out
+}
+
+// tokenEntryView shows the token status, followed by the field while a
+// token is being entered.
+func (m model) tokenEntryView() string {
+	if !m.tokenInput.Focused() {
+		return m.tokenStatus
+	}
+	return m.tokenStatus + "\n" + m.tokenInputView()
+}
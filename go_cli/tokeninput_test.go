@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string // part of the error, "" for a valid token
+	}{
+		{"hf_" + strings.Repeat("a", 20), ""},
+		{"hf_AbCdEf0123456789xYz9876", ""},
+		{"", "empty"},
+		{"hg_" + strings.Repeat("a", 20), `start with "hf_"`},
+		{"HF_" + strings.Repeat("a", 20), `start with "hf_"`},
+		{"hf_", "at least 20"},
+		{"hf_" + strings.Repeat("a", 19), "at least 20"},
+		{"hf_" + strings.Repeat("a", 19) + "-", "at least 20"},
+		{"hf_" + strings.Repeat("a", 20) + " ", "at least 20"},
+		{"hf_" + strings.Repeat("é", 20), "at least 20"},
+	}
+	for _, tt := range tests {
+		err := validateToken(tt.token)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateToken(%q) = %v, want ok", tt.token, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("validateToken(%q) = %v, want %q", tt.token, err, tt.want)
+		}
+	}
+}
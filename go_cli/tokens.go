@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/audit"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/hub"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/profiles"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+)
+
+// defaultProfileName names a token entered in the fine-tune flow.
+const defaultProfileName = "default"
+
+var profileStore = openProfileStore()
+
+// profileEdit is what the token menu is currently asking for.
+type profileEdit int
+
+const (
+	editNone profileEdit = iota
+	editAddName
+	editAddToken
+	editRename
+	editConfirmDelete
+)
+
+// --- Token Profiles ---
+// loadProfiles reads the profile list and registers every token as a
+// secret so it never shows up in logs or views.
+func loadProfiles() profiles.Set {
+	set, err := profileStore.Load()
+	if err != nil && !errors.Is(err, profiles.ErrLocked) {
+		logEvent(eventlog.Error, eventlog.TypeToken, "Failed to read token profiles", eventlog.Fields{"error": err.Error()})
+	}
+	for _, p := range set.Profiles {
+		redact.AddSecret(p.Token)
+	}
+	return set
+}
+
+// activeProfile returns the active profile, if any.
+func activeProfile() (profiles.Profile, bool) {
+	set := loadProfiles()
+	return set.ActiveProfile()
+}
+
+func newNameInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "profile name"
+	ti.CharLimit = 32
+	ti.Width = 32
+	return ti
+}
+
+// openTokenMenu loads the profiles and selects the active one.
+func (m *model) openTokenMenu() {
+	m.state = tokenMenu
+	m.tokenStatus = ""
+	m.profileEdit = editNone
+	m.profiles = loadProfiles()
+	m.profileIdx = max(m.profiles.Index(m.profiles.Active), 0)
+}
+
+func (m model) selectedProfile() (profiles.Profile, bool) {
+	if m.profileIdx < 0 || m.profileIdx >= len(m.profiles.Profiles) {
+		return profiles.Profile{}, false
+	}
+	return m.profiles.Profiles[m.profileIdx], true
+}
+
+// updateTokenMenu handles keys in the profile list and its prompts.
+func (m model) updateTokenMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.profileEdit {
+	case editAddName, editRename:
+		return m.updateNameInput(msg)
+	case editAddToken:
+		if msg.Type == tea.KeyEsc {
+			m.stopTokenInput()
+			m.profileEdit = editNone
+			m.tokenStatus = ""
+			return m, nil
+		}
+		name := m.pendingProfile
+		cmd := m.updateTokenInput(msg, func(token string) tea.Cmd {
+			return addProfile(name, token)
+		})
+		if !m.tokenInput.Focused() {
+			m.profileEdit = editNone
+			m.tokenStatus = "Checking token for " + name + "..."
+		}
+		return m, cmd
+	case editConfirmDelete:
+		p, ok := m.selectedProfile()
+		m.profileEdit = editNone
+		m.tokenStatus = ""
+		if ok && msg.String() == "y" {
+			return m, deleteProfile(p.Name)
+		}
+		return m, nil
+	}
+
+	if profileStore.Locked() && msg.String() != "u" && msg.String() != "esc" && msg.String() != "q" {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "q":
+		m.state = mainMenu
+	case "u":
+		if profileStore.Locked() {
+			return m, m.startVaultUnlock(tokenMenu)
+		}
+	case "L":
+		if profileStore.Encrypted() && !profileStore.Locked() {
+			m.profiles = profiles.Set{}
+			m.tokenIdentity = nil
+			return m, lockVault
+		}
+	case "j", "down":
+		if n := len(m.profiles.Profiles); n > 0 {
+			m.profileIdx = (m.profileIdx + 1) % n
+		}
+	case "k", "up":
+		if n := len(m.profiles.Profiles); n > 0 {
+			m.profileIdx = (m.profileIdx + n - 1) % n
+		}
+	case "enter":
+		if p, ok := m.selectedProfile(); ok {
+			m.tokenStatus = "Activating " + p.Name + "..."
+			return m, activateProfile(p.Name)
+		}
+	case "a":
+		m.profileEdit = editAddName
+		m.tokenStatus = "Name for the new profile:"
+		m.nameInput.Reset()
+		return m, m.nameInput.Focus()
+	case "r":
+		if p, ok := m.selectedProfile(); ok {
+			m.profileEdit = editRename
+			m.tokenStatus = "Rename " + p.Name + " to:"
+			m.nameInput.SetValue(p.Name)
+			return m, m.nameInput.Focus()
+		}
+	case "d":
+		if p, ok := m.selectedProfile(); ok {
+			m.profileEdit = editConfirmDelete
+			m.tokenStatus = "Delete profile " + p.Name + "? [y/N]"
+		}
+	case "v":
+		if p, ok := m.selectedProfile(); ok {
+			m.tokenStatus = "Checking " + p.Name + "..."
+			return m, func() tea.Msg { return checkToken("Profile "+p.Name+": "+redact.Token(p.Token), p.Token) }
+		}
+	}
+	return m, nil
+}
+
+// updateNameInput edits the name of a new or renamed profile.
+func (m model) updateNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.nameInput.Blur()
+		m.profileEdit = editNone
+		m.tokenStatus = ""
+		m.tokenError = ""
+		return m, nil
+	case tea.KeyEnter:
+	default:
+		var cmd tea.Cmd
+		m.nameInput, cmd = m.nameInput.Update(msg)
+		m.tokenError = ""
+		return m, cmd
+	}
+	name := strings.TrimSpace(m.nameInput.Value())
+	if err := profiles.ValidateName(name); err != nil {
+		m.tokenError = err.Error()
+		return m, nil
+	}
+	if m.profileEdit == editRename {
+		p, _ := m.selectedProfile()
+		if name != p.Name && m.profiles.Index(name) >= 0 {
+			m.tokenError = "a profile named " + name + " already exists"
+			return m, nil
+		}
+		m.nameInput.Blur()
+		m.profileEdit = editNone
+		m.tokenStatus = ""
+		return m, renameProfile(p.Name, name)
+	}
+	if m.profiles.Index(name) >= 0 {
+		m.tokenError = "a profile named " + name + " already exists"
+		return m, nil
+	}
+	m.nameInput.Blur()
+	m.pendingProfile = name
+	m.profileEdit = editAddToken
+	m.tokenStatus = "Token for " + name + ":"
+	return m, m.startTokenInput()
+}
+
+// profilesMsg carries the profile list after a change.
+type profilesMsg struct {
+	set    profiles.Set
+	status string
+	err    error
+}
+
+// addProfile validates token against the hub, stores it under name and
+// makes it the active token.
+func addProfile(name, token string) tea.Cmd {
+	return func() tea.Msg {
+		redact.AddSecret(token)
+		id, err := whoAmI(token)
+		if errors.Is(err, hub.ErrUnauthorized) {
+			return tokenCheckMsg{status: "Token rejected by " + appConfig.HubURL + "; profile not saved", rejected: true, err: err}
+		}
+		if _, err := profileStore.Update(func(s *profiles.Set) error {
+			if err := s.Add(name, token); err != nil {
+				return err
+			}
+			return s.SetActive(name)
+		}); err != nil {
+			return tokenStatusMsg("Failed to save profile: " + err.Error())
+		}
+		logEvent(eventlog.Info, eventlog.TypeToken, "Token profile added", eventlog.Fields{"profile": name})
+		if err := os.Setenv("HF_TOKEN", token); err != nil {
+			return tokenStatusMsg("Failed to set token: " + err.Error())
+		}
+		recordAudit(audit.TokenSet, "profile:"+name, tokenAuditDetails(token, id, err))
+		status := "Token set successfully"
+		if err != nil {
+			return tokenCheckMsg{status: status, err: err}
+		}
+		return tokenCheckMsg{status: status, identity: &id}
+	}
+}
+
+// activateProfile makes name the active profile and validates its token.
+func activateProfile(name string) tea.Cmd {
+	return func() tea.Msg {
+		set, err := profileStore.Update(func(s *profiles.Set) error { return s.SetActive(name) })
+		if err != nil {
+			return profilesMsg{err: err}
+		}
+		p, _ := set.ActiveProfile()
+		logEvent(eventlog.Info, eventlog.TypeToken, "Token profile activated", eventlog.Fields{"profile": name})
+		return setToken(p.Token)()
+	}
+}
+
+func renameProfile(old, name string) tea.Cmd {
+	return func() tea.Msg {
+		set, err := profileStore.Update(func(s *profiles.Set) error { return s.Rename(old, name) })
+		if err == nil {
+			logEvent(eventlog.Info, eventlog.TypeToken, "Token profile renamed", eventlog.Fields{"profile": name, "old": old})
+		}
+		return profilesMsg{set: set, status: "Renamed " + old + " to " + name, err: err}
+	}
+}
+
+// deleteProfile removes name; if it was active, the token is cleared too.
+func deleteProfile(name string) tea.Cmd {
+	return func() tea.Msg {
+		wasActive := false
+		set, err := profileStore.Update(func(s *profiles.Set) error {
+			wasActive = s.Active == name
+			return s.Delete(name)
+		})
+		if err != nil {
+			return profilesMsg{set: set, err: err}
+		}
+		logEvent(eventlog.Info, eventlog.TypeToken, "Token profile deleted", eventlog.Fields{"profile": name})
+		recordAudit(audit.TokenClear, "profile:"+name, nil)
+		if wasActive {
+			os.Unsetenv("HF_TOKEN")
+		}
+		return profilesMsg{set: set, status: "Deleted " + name}
+	}
+}
+
+// pushActiveToken hands the active profile's token to the session server,
+// where the trainer picks it up, and returns the profile's name. Without
+// profiles the session server keeps whatever token it already has.
+func pushActiveToken() (string, error) {
+	p, ok := activeProfile()
+	if !ok {
+		return "", nil
+	}
+	ttl, err := sessionClient().SetToken(p.Token)
+	if err != nil {
+		return p.Name, fmt.Errorf("pushing token of profile %s to session server: %w", p.Name, err)
+	}
+	logSessionToken(p.Name, ttl)
+	return p.Name, nil
+}
+
+// logSessionToken records a token handed to the session server and how
+// long the server keeps it, for the token expiry metric.
+func logSessionToken(profile string, ttl time.Duration) {
+	logEvent(eventlog.Info, eventlog.TypeToken, "Token stored in session server", eventlog.Fields{
+		"profile": profile, "expires_in_s": int(ttl.Seconds()),
+	})
+}
+
+func (m model) tokenMenuView() string {
+	out := headerStyle.Render("Token Profiles") + "\n\n"
+	if profileStore.Locked() {
+		out += "The token vault is locked.\n"
+		if m.tokenStatus != "" {
+			out += "\n" + m.tokenStatus + "\n"
+		}
+		return out + "\n[u] Unlock  [ESC] Back"
+	}
+	if len(m.profiles.Profiles) == 0 {
+		out += "No profiles yet. Press a to add one.\n"
+	}
+	if !profileStore.Encrypted() {
+		out += "Profiles last until exit; run nexa vault init to keep them encrypted.\n"
+	}
+	for i, p := range m.profiles.Profiles {
+		marker := "  "
+		if p.Name == m.profiles.Active {
+			marker = "● "
+		}
+		line := fmt.Sprintf("%s%-20s %s", marker, p.Name, redact.Token(p.Token))
+		if i == m.profileIdx {
+			out += selectedStyle.Render(line) + "\n"
+		} else {
+			out += "  " + line + "\n"
+		}
+	}
+	out += "\n"
+	switch {
+	case m.profileEdit == editAddName || m.profileEdit == editRename:
+		out += m.tokenStatus + "\n" + m.nameInput.View()
+		if m.tokenError != "" {
+			out += "\n" + errorStyle.Render(m.tokenError)
+		}
+	default:
+		out += m.tokenEntryView()
+	}
+	if id := m.identityView(); id != "" && m.profileEdit == editNone {
+		out += "\n" + id
+	}
+	keys := "\n\n[Enter] Activate  [a] Add  [r] Rename  [d] Delete  [v] Verify"
+	if profileStore.Encrypted() {
+		keys += "  [L] Lock vault"
+	}
+	return out + keys + "  [ESC] Back"
+}
@@ -0,0 +1,113 @@
+// Package trainerstate parses the trainer_state.json files that the Hugging
+// Face Trainer writes into each checkpoint, giving access to the loss curve
+// and training hyperparameters of a run.
+package trainerstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileName is the name of the state file inside a checkpoint directory.
+const FileName = "trainer_state.json"
+
+// Point is one logged training step.
+type Point struct {
+	Step         int     `json:"step"`
+	Epoch        float64 `json:"epoch"`
+	Loss         float64 `json:"loss"`
+	LearningRate float64 `json:"learning_rate"`
+}
+
+// State is the subset of trainer_state.json used by Nexa.
+type State struct {
+	Path           string           `json:"-"`
+	GlobalStep     int              `json:"global_step"`
+	MaxSteps       int              `json:"max_steps"`
+	Epoch          float64          `json:"epoch"`
+	NumTrainEpochs int              `json:"num_train_epochs"`
+	TrainBatchSize int              `json:"train_batch_size"`
+	LoggingSteps   int              `json:"logging_steps"`
+	SaveSteps      int              `json:"save_steps"`
+	LogHistory     []map[string]any `json:"log_history"`
+}
+
+// Load parses a trainer_state.json file.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.Path = path
+	return &s, nil
+}
+
+// Find loads the trainer state of the run in dir, preferring a state file
+// at the top level and falling back to the latest checkpoint.
+func Find(dir string) (*State, error) {
+	if s, err := Load(filepath.Join(dir, FileName)); err == nil {
+		return s, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "checkpoint-*", FileName))
+	best, bestStep := "", -1
+	for _, m := range matches {
+		name := filepath.Base(filepath.Dir(m))
+		step, err := strconv.Atoi(strings.TrimPrefix(name, "checkpoint-"))
+		if err == nil && step > bestStep {
+			best, bestStep = m, step
+		}
+	}
+	if best == "" {
+		return nil, fmt.Errorf("no %s found in %s", FileName, dir)
+	}
+	return Load(best)
+}
+
+// Losses returns the logged training loss points in step order. Entries
+// logged for the same step keep their log_history order.
+func (s *State) Losses() []Point {
+	var pts []Point
+	for _, e := range s.LogHistory {
+		loss, ok := e["loss"].(float64)
+		if !ok {
+			continue
+		}
+		step, _ := e["step"].(float64)
+		epoch, _ := e["epoch"].(float64)
+		lr, _ := e["learning_rate"].(float64)
+		pts = append(pts, Point{Step: int(step), Epoch: epoch, Loss: loss, LearningRate: lr})
+	}
+	sort.SliceStable(pts, func(i, j int) bool { return pts[i].Step < pts[j].Step })
+	return pts
+}
+
+// FinalLoss returns the last logged training loss.
+func (s *State) FinalLoss() (float64, bool) {
+	pts := s.Losses()
+	if len(pts) == 0 {
+		return 0, false
+	}
+	return pts[len(pts)-1].Loss, true
+}
+
+// BestLoss returns the lowest logged training loss.
+func (s *State) BestLoss() (float64, bool) {
+	pts := s.Losses()
+	if len(pts) == 0 {
+		return 0, false
+	}
+	best := pts[0].Loss
+	for _, p := range pts[1:] {
+		best = min(best, p.Loss)
+	}
+	return best, true
+}
@@ -0,0 +1,63 @@
+package trainerstate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLosses(t *testing.T) {
+	// A resumed run can append entries for earlier steps after later ones.
+	path := filepath.Join(t.TempDir(), FileName)
+	data := `{"global_step": 30, "log_history": [
+		{"step": 20, "loss": 1.0},
+		{"step": 10, "loss": 2.0, "learning_rate": 0.001},
+		{"step": 30, "eval_loss": 1.4},
+		{"step": 30, "loss": 1.5},
+		{"step": 30, "loss": 1.2}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Point{{Step: 10, Loss: 2.0, LearningRate: 0.001}, {Step: 20, Loss: 1.0}, {Step: 30, Loss: 1.5}, {Step: 30, Loss: 1.2}}
+	if got := s.Losses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Losses() = %+v, want %+v", got, want)
+	}
+	if v, ok := s.FinalLoss(); !ok || v != 1.2 {
+		t.Errorf("FinalLoss() = %v, %v, want 1.2", v, ok)
+	}
+	if v, ok := s.BestLoss(); !ok || v != 1.0 {
+		t.Errorf("BestLoss() = %v, %v, want 1.0", v, ok)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	for _, step := range []string{"5", "20", "100"} {
+		cp := filepath.Join(dir, "checkpoint-"+step)
+		if err := os.MkdirAll(cp, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cp, FileName), []byte(`{"global_step": `+step+`}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The latest checkpoint by step, not by name.
+	if s, err := Find(dir); err != nil || s.GlobalStep != 100 {
+		t.Errorf("Find() = %+v, %v, want checkpoint-100", s, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{"global_step": 120}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Find(dir); err != nil || s.GlobalStep != 120 {
+		t.Errorf("Find() = %+v, %v, want the top-level state", s, err)
+	}
+	if _, err := Find(t.TempDir()); err == nil {
+		t.Error("Find() in an empty directory succeeded")
+	}
+}
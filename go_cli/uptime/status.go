@@ -0,0 +1,74 @@
+package uptime
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// recentIncidents is how many incidents Status lists.
+const recentIncidents = 20
+
+// Status is a snapshot of the history as served by the monitor's /status
+// endpoint.
+type Status struct {
+	Updated   time.Time        `json:"updated"`
+	Endpoints []EndpointStatus `json:"endpoints"`
+	// Incidents are the most recent first.
+	Incidents []IncidentStatus `json:"incidents"`
+}
+
+// EndpointStatus is the current state of one endpoint.
+type EndpointStatus struct {
+	Backend    string               `json:"backend"`
+	Endpoint   string               `json:"endpoint"`
+	Up         bool                 `json:"up"`
+	Since      time.Time            `json:"since"`
+	Last       Probe                `json:"last"`
+	Components map[string]Component `json:"components,omitempty"`
+	// Uptime is the percentage of successful probes by window name;
+	// windows without probes are left out.
+	Uptime map[string]float64 `json:"uptime"`
+}
+
+// IncidentStatus is an incident with its duration so far.
+type IncidentStatus struct {
+	Incident
+	Ongoing   bool    `json:"ongoing"`
+	DurationS float64 `json:"duration_s"`
+}
+
+// Status returns the state of every endpoint and the recent incidents at
+// now.
+func (h *History) Status(now time.Time) Status {
+	s := Status{Updated: h.Updated, Endpoints: []EndpointStatus{}, Incidents: []IncidentStatus{}}
+	for _, e := range h.Endpoints {
+		es := EndpointStatus{
+			Backend:    e.Backend,
+			Endpoint:   e.URL,
+			Up:         e.Last.OK,
+			Since:      e.Since,
+			Last:       e.Last,
+			Components: maps.Clone(e.Components),
+			Uptime:     map[string]float64{},
+		}
+		for _, w := range Windows {
+			if pct, ok := e.Uptime(w.Duration, now); ok {
+				es.Uptime[w.Name] = math.Round(pct*100) / 100
+			}
+		}
+		s.Endpoints = append(s.Endpoints, es)
+	}
+	for _, in := range slices.Backward(h.Incidents) {
+		if len(s.Incidents) == recentIncidents {
+			break
+		}
+		s.Incidents = append(s.Incidents, IncidentStatus{
+			Incident:  in,
+			Ongoing:   in.Ongoing(),
+			DurationS: math.Round(in.Duration(now).Seconds()),
+		})
+	}
+	return s
+}
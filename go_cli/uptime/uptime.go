@@ -0,0 +1,259 @@
+// Package uptime keeps the probe history of the monitored backends: the
+// last result and state change of every endpoint, per-minute availability
+// for the rolling uptime windows, and recent incidents. The history is a
+// small JSON file so the statistics survive restarts of the monitor and can
+// be read by the TUI.
+package uptime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/xdg"
+)
+
+const (
+	// Retention is how long availability and incidents are kept; it is
+	// also the longest uptime window.
+	Retention = 7 * 24 * time.Hour
+	// bucketSize is the resolution of the availability history.
+	bucketSize = time.Minute
+	// maxIncidents bounds the incidents kept within the retention.
+	maxIncidents = 100
+)
+
+// Window is a rolling period uptime is reported over.
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the uptime periods reported by Status.
+var Windows = []Window{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", Retention},
+}
+
+// DefaultPath returns the history file location in the XDG state directory.
+func DefaultPath() string {
+	return filepath.Join(xdg.StateHome(), "monitor.json")
+}
+
+// Probe is the outcome of one health probe.
+type Probe struct {
+	Time      time.Time `json:"time"`
+	OK        bool      `json:"ok"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS float64   `json:"latency_ms"`
+}
+
+// Component is the last reported status of one component of a backend and
+// when that status was first seen.
+type Component struct {
+	Status string    `json:"status"`
+	Since  time.Time `json:"since"`
+}
+
+// Bucket counts the probes of one minute.
+type Bucket struct {
+	// Start is the start of the minute in Unix seconds.
+	Start int64 `json:"t"`
+	Up    int   `json:"up"`
+	Total int   `json:"n"`
+}
+
+// Endpoint is the probe history of one backend endpoint.
+type Endpoint struct {
+	Backend string `json:"backend"`
+	URL     string `json:"endpoint"`
+	Last    Probe  `json:"last"`
+	// Since is when the endpoint last changed between healthy and
+	// unhealthy, or was first probed.
+	Since      time.Time            `json:"since"`
+	Components map[string]Component `json:"components,omitempty"`
+	Buckets    []Bucket             `json:"buckets"`
+}
+
+// Uptime returns the percentage of successful probes within window before
+// now, and false when there were none.
+func (e *Endpoint) Uptime(window time.Duration, now time.Time) (float64, bool) {
+	from := now.Add(-window).Truncate(bucketSize).Unix()
+	up, total := 0, 0
+	for _, b := range e.Buckets {
+		if b.Start > from {
+			up += b.Up
+			total += b.Total
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(up) / float64(total), true
+}
+
+// Incident is a period during which an endpoint was unhealthy.
+type Incident struct {
+	Backend  string    `json:"backend"`
+	Endpoint string    `json:"endpoint"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end,omitzero"`
+	// Reason is the error or status of the probe that opened the incident.
+	Reason string `json:"reason"`
+}
+
+// Ongoing reports whether the endpoint is still unhealthy.
+func (i Incident) Ongoing() bool {
+	return i.End.IsZero()
+}
+
+// Duration returns how long the incident lasted, or has lasted so far.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.Ongoing() {
+		return now.Sub(i.Start)
+	}
+	return i.End.Sub(i.Start)
+}
+
+// History is the probe history of every endpoint.
+type History struct {
+	Updated   time.Time   `json:"updated"`
+	Endpoints []*Endpoint `json:"endpoints"`
+	// Incidents are ordered by start time, oldest first.
+	Incidents []Incident `json:"incidents"`
+}
+
+// Record adds one probe of the named backend at now and reports whether it
+// changed the endpoint's state, which includes its first probe.
+func (h *History) Record(name string, p backend.Health, now time.Time) bool {
+	h.Updated = now
+	e := h.endpoint(name, p.Endpoint)
+	probe := Probe{Time: now, OK: p.OK(), Status: p.Status, LatencyMS: float64(p.Latency) / float64(time.Millisecond)}
+	if p.Err != nil {
+		probe.Error = p.Err.Error()
+	}
+	first := e.Last.Time.IsZero()
+	changed := first || e.Last.OK != probe.OK
+	e.Last = probe
+	if changed {
+		e.Since = now
+		h.transition(e, probe)
+	}
+
+	for c, status := range p.Components {
+		if old, ok := e.Components[c]; !ok || old.Status != status {
+			e.Components[c] = Component{Status: status, Since: now}
+		}
+	}
+
+	start := now.Truncate(bucketSize).Unix()
+	if n := len(e.Buckets); n == 0 || e.Buckets[n-1].Start != start {
+		e.Buckets = append(e.Buckets, Bucket{Start: start})
+	}
+	b := &e.Buckets[len(e.Buckets)-1]
+	b.Total++
+	if probe.OK {
+		b.Up++
+	}
+	return changed
+}
+
+// transition opens an incident when e became unhealthy and closes it when
+// e recovered.
+func (h *History) transition(e *Endpoint, p Probe) {
+	if !p.OK {
+		reason := p.Error
+		if reason == "" {
+			reason = p.Status
+		}
+		h.Incidents = append(h.Incidents, Incident{Backend: e.Backend, Endpoint: e.URL, Start: p.Time, Reason: reason})
+		return
+	}
+	for i := len(h.Incidents) - 1; i >= 0; i-- {
+		in := &h.Incidents[i]
+		if in.Backend == e.Backend && in.Endpoint == e.URL && in.Ongoing() {
+			in.End = p.Time
+			return
+		}
+	}
+}
+
+func (h *History) endpoint(name, url string) *Endpoint {
+	for _, e := range h.Endpoints {
+		if e.Backend == name && e.URL == url {
+			return e
+		}
+	}
+	e := &Endpoint{Backend: name, URL: url, Components: map[string]Component{}}
+	h.Endpoints = append(h.Endpoints, e)
+	return e
+}
+
+// Prune drops availability and closed incidents older than the retention
+// at now, and all but the latest incidents. Endpoints not probed within the
+// retention, such as ones removed from the configuration, are dropped and
+// their ongoing incidents closed at their last probe.
+func (h *History) Prune(now time.Time) {
+	cutoff := now.Add(-Retention)
+	from := cutoff.Truncate(bucketSize).Unix()
+	h.Endpoints = slices.DeleteFunc(h.Endpoints, func(e *Endpoint) bool {
+		e.Buckets = slices.DeleteFunc(e.Buckets, func(b Bucket) bool { return b.Start < from })
+		if len(e.Buckets) > 0 {
+			return false
+		}
+		h.transition(e, Probe{Time: e.Last.Time, OK: true})
+		return true
+	})
+	h.Incidents = slices.DeleteFunc(h.Incidents, func(i Incident) bool {
+		return !i.Ongoing() && i.End.Before(cutoff)
+	})
+	if n := len(h.Incidents); n > maxIncidents {
+		h.Incidents = slices.Delete(h.Incidents, 0, n-maxIncidents)
+	}
+}
+
+// Load reads the history at path. A missing file yields an empty history.
+func Load(path string) (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return &History{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, e := range h.Endpoints {
+		if e.Components == nil {
+			e.Components = map[string]Component{}
+		}
+	}
+	return h, nil
+}
+
+// Save writes the history to path atomically so readers never see a torn
+// file. The file is owner-only, as the endpoints and errors it records may
+// name internal hosts.
+func (h *History) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
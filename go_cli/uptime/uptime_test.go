@@ -0,0 +1,178 @@
+package uptime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/backend"
+)
+
+const endpoint = "http://localhost:8770"
+
+func health(ok bool) backend.Health {
+	if ok {
+		return backend.Health{Endpoint: endpoint, Status: "ok", Components: map[string]string{"trainer": "ok"}}
+	}
+	return backend.Health{Endpoint: endpoint, Status: "unreachable", Err: errors.New("connection refused")}
+}
+
+// record probes the trainer once a minute from start, up as given.
+func record(h *History, start time.Time, up ...bool) []bool {
+	changed := make([]bool, len(up))
+	for i, ok := range up {
+		changed[i] = h.Record("trainer", health(ok), start.Add(time.Duration(i)*time.Minute))
+	}
+	return changed
+}
+
+func TestRecordTransitions(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+	changed := record(h, start, true, true, false, false, false, true, true)
+	want := []bool{true, false, true, false, false, true, false}
+	for i := range want {
+		if changed[i] != want[i] {
+			t.Errorf("probe %d changed = %v, want %v", i, changed[i], want[i])
+		}
+	}
+
+	if len(h.Incidents) != 1 {
+		t.Fatalf("incidents = %+v, want one", h.Incidents)
+	}
+	in := h.Incidents[0]
+	if !in.Start.Equal(start.Add(2*time.Minute)) || !in.End.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("incident = %s..%s, want minutes 2..5", in.Start, in.End)
+	}
+	if in.Reason != "connection refused" {
+		t.Errorf("reason = %q", in.Reason)
+	}
+
+	e := h.Endpoints[0]
+	if !e.Since.Equal(start.Add(5 * time.Minute)) {
+		t.Errorf("since = %s, want the recovery", e.Since)
+	}
+	if c := e.Components["trainer"]; c.Status != "ok" || !c.Since.Equal(start) {
+		t.Errorf("component = %+v, want ok since the first probe", c)
+	}
+}
+
+func TestUptimeWindows(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+	// Down for the first 60 minutes, then up for 60.
+	up := make([]bool, 120)
+	for i := 60; i < 120; i++ {
+		up[i] = true
+	}
+	record(h, start, up...)
+	now := start.Add(119 * time.Minute)
+
+	s := h.Status(now)
+	got := s.Endpoints[0].Uptime
+	if got["1h"] != 100 {
+		t.Errorf("1h uptime = %v, want 100", got["1h"])
+	}
+	if got["24h"] != 50 || got["7d"] != 50 {
+		t.Errorf("uptime = %v, want 50 over 24h and 7d", got)
+	}
+	if !s.Endpoints[0].Up || len(s.Incidents) != 1 || s.Incidents[0].Ongoing {
+		t.Errorf("status = %+v", s)
+	}
+
+	var empty Endpoint
+	if _, ok := empty.Uptime(time.Hour, now); ok {
+		t.Error("uptime of an endpoint without probes reported as known")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+	record(h, start, false, true)
+	later := start.Add(Retention + time.Hour)
+	h.Record("trainer", health(false), later)
+
+	h.Prune(later)
+	if n := len(h.Endpoints[0].Buckets); n != 1 {
+		t.Errorf("buckets = %d, want only the recent one", n)
+	}
+	if len(h.Incidents) != 1 || !h.Incidents[0].Ongoing() {
+		t.Errorf("incidents = %+v, want only the ongoing one", h.Incidents)
+	}
+}
+
+func TestPruneRemovedEndpoint(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+	// The old endpoint went down and was then removed from the config;
+	// only the new one is probed afterwards.
+	record(h, start, true, false)
+	moved := health(true)
+	moved.Endpoint = "http://gpu-box:8770"
+	later := start.Add(Retention + time.Hour)
+	h.Record("trainer", moved, later)
+
+	h.Prune(later)
+	if len(h.Endpoints) != 1 || h.Endpoints[0].URL != moved.Endpoint {
+		t.Fatalf("endpoints = %+v, want only the probed one", h.Endpoints)
+	}
+	// Its incident was closed at the last probe, which is past the
+	// retention, so it is gone as well.
+	if len(h.Incidents) != 0 {
+		t.Errorf("incidents = %+v, want none", h.Incidents)
+	}
+	if s := h.Status(later); len(s.Endpoints) != 1 {
+		t.Errorf("status lists %d endpoints, want 1", len(s.Endpoints))
+	}
+}
+
+func TestSaveMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	path := filepath.Join(dir, "monitor.json")
+	h := &History{}
+	record(h, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), true)
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	for p, want := range map[string]os.FileMode{dir: 0o700, path: 0o600} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fi.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %s, want %s", p, got, want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "monitor.json")
+	h, err := Load(path)
+	if err != nil || len(h.Endpoints) != 0 {
+		t.Fatalf("Load of a missing file = %+v, %v", h, err)
+	}
+
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	record(h, start, true, false)
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := start.Add(time.Minute)
+	if got, want := loaded.Status(now), h.Status(now); got.Endpoints[0].Uptime["1h"] != want.Endpoints[0].Uptime["1h"] ||
+		len(got.Incidents) != 1 || !got.Incidents[0].Ongoing {
+		t.Errorf("loaded status = %+v, want %+v", got, want)
+	}
+
+	// A restarted monitor continues the endpoint rather than treating its
+	// next probe as a state change.
+	if loaded.Record("trainer", health(false), now.Add(time.Minute)) {
+		t.Error("probe after reload reported a state change")
+	}
+}
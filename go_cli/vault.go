@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/DarkStarStrix/nexa_auto_go_cli/audit"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/eventlog"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/profiles"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/redact"
+	"github.com/DarkStarStrix/nexa_auto_go_cli/vault"
+)
+
+// passphraseEnv lets scripts unlock the vault without a terminal.
+const passphraseEnv = "NEXA_VAULT_PASSPHRASE"
+
+// openProfileStore uses the encrypted vault once it has been created with
+// "nexa vault init". Without one, profiles are kept in memory for this run,
+// starting from any plain profiles file an earlier version left behind.
+func openProfileStore() *profiles.Store {
+	if vault.Exists(profiles.VaultPath()) {
+		return profiles.OpenVault(profiles.VaultPath())
+	}
+	set, _ := profiles.Open(profiles.PlainPath()).Load()
+	return profiles.Memory(set)
+}
+
+// --- Vault (TUI) ---
+type vaultUnlockedMsg struct {
+	err    error
+	status string
+}
+
+func newPassphraseInput() textinput.Model {
+	ti := newTokenInput()
+	ti.Placeholder = "passphrase"
+	return ti
+}
+
+// startVaultUnlock shows the passphrase prompt, returning to ret afterwards.
+func (m *model) startVaultUnlock(ret state) tea.Cmd {
+	m.state = vaultUnlock
+	m.vaultReturn = ret
+	m.vaultStatus = ""
+	m.vaultInput.Reset()
+	return m.vaultInput.Focus()
+}
+
+// unlockVault unlocks the profile vault and loads the active profile's
+// token into the session server.
+func unlockVault(passphrase []byte) tea.Cmd {
+	return func() tea.Msg {
+		defer clear(passphrase)
+		if err := profileStore.Unlock(passphrase); err != nil {
+			logEvent(eventlog.Warn, eventlog.TypeToken, "Vault unlock failed", eventlog.Fields{"error": err.Error()})
+			return vaultUnlockedMsg{err: err}
+		}
+		logEvent(eventlog.Info, eventlog.TypeToken, "Vault unlocked", nil)
+		p, ok := activeProfile()
+		if !ok {
+			return vaultUnlockedMsg{status: "Vault unlocked"}
+		}
+		os.Setenv("HF_TOKEN", p.Token)
+		recordAudit(audit.TokenSet, "profile:"+p.Name, map[string]string{"token": tokenFingerprint(p.Token), "via": "vault unlock"})
+		ttl, err := sessionClient().SetToken(p.Token)
+		if err != nil {
+			return vaultUnlockedMsg{status: "Vault unlocked; session server unavailable: " + err.Error()}
+		}
+		logSessionToken(p.Name, ttl)
+		return vaultUnlockedMsg{status: "Vault unlocked; loaded profile " + p.Name + " into the session server"}
+	}
+}
+
+// lockVault forgets the vault key and removes the token from this process
+// and the session server.
+func lockVault() tea.Msg {
+	profileStore.Lock()
+	os.Unsetenv("HF_TOKEN")
+	logEvent(eventlog.Info, eventlog.TypeToken, "Vault locked", eventlog.Fields{"expires_in_s": 0})
+	recordAudit(audit.TokenClear, "HF_TOKEN", map[string]string{"via": "vault lock"})
+	if err := sessionClient().ClearToken(); err != nil {
+		return profilesMsg{status: "Vault locked; could not clear session server: " + err.Error()}
+	}
+	return profilesMsg{status: "Vault locked"}
+}
+
+func (m model) updateVaultUnlock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.vaultInput.Reset()
+		m.vaultInput.Blur()
+		if m.vaultReturn == tokenMenu {
+			m.openTokenMenu()
+		} else {
+			m.state = m.vaultReturn
+		}
+		return m, nil
+	case tea.KeyEnter:
+		if m.vaultInput.Value() == "" {
+			return m, nil
+		}
+		passphrase := []byte(m.vaultInput.Value())
+		m.vaultInput.Reset()
+		m.vaultStatus = "Unlocking..."
+		return m, unlockVault(passphrase)
+	}
+	var cmd tea.Cmd
+	m.vaultInput, cmd = m.vaultInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) vaultUnlockView() string {
+	out := headerStyle.Render("Unlock Token Vault") + "\n\n"
+	out += "Passphrase for " + profileStore.Path() + "\n"
+	out += m.vaultInput.View() + "\n\n"
+	if m.vaultStatus != "" {
+		out += m.vaultStatus + "\n\n"
+	}
+	return out + "[Enter] Unlock  [ESC] Continue without tokens"
+}
+
+// --- Vault Command ---
+func runVault(args []string) error {
+	const usage = "usage: nexa vault init|status|lock|wipe [--yes]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	fset := flag.NewFlagSet("vault", flag.ContinueOnError)
+	yes := fset.Bool("yes", false, "do not ask for confirmation (wipe)")
+	if err := fset.Parse(args[1:]); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "init":
+		return vaultInit()
+	case "status":
+		return vaultStatus()
+	case "lock":
+		return vaultLock()
+	case "wipe":
+		return vaultWipe(*yes)
+	}
+	return errors.New(usage)
+}
+
+// vaultInit creates the vault, moving any plain profiles into it and
+// wiping the plaintext file.
+func vaultInit() error {
+	path := profiles.VaultPath()
+	if vault.Exists(path) {
+		return fmt.Errorf("vault already exists at %s", path)
+	}
+	plain := profiles.Open(profiles.PlainPath())
+	set, err := plain.Load()
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase("New vault passphrase: ")
+	if err != nil {
+		return err
+	}
+	defer clear(passphrase)
+	if os.Getenv(passphraseEnv) == "" {
+		again, err := readPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		match := string(again) == string(passphrase)
+		clear(again)
+		if !match {
+			return errors.New("passphrases do not match")
+		}
+	}
+	store := profiles.OpenVault(path)
+	if err := store.Unlock(passphrase); err != nil {
+		return err
+	}
+	defer store.Lock()
+	if _, err := store.Update(func(s *profiles.Set) error { *s = set; return nil }); err != nil {
+		return err
+	}
+	if err := vault.Wipe(profiles.PlainPath()); err != nil {
+		return fmt.Errorf("vault created, but removing plaintext profiles failed: %w", err)
+	}
+	logEvent(eventlog.Info, eventlog.TypeToken, "Vault created", eventlog.Fields{"profiles": len(set.Profiles)})
+	fmt.Printf("Vault created at %s with %d profile(s)\n", path, len(set.Profiles))
+	return nil
+}
+
+func vaultStatus() error {
+	path := profiles.VaultPath()
+	if !vault.Exists(path) {
+		fmt.Println("No vault; token profiles are kept in memory and lost on exit")
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Vault: %s (mode %s, modified %s)\n", path, fi.Mode().Perm(), fi.ModTime().Format("2006-01-02 15:04"))
+	return nil
+}
+
+// vaultLock removes the unlocked token from the session server, so jobs
+// need the vault to be unlocked again.
+func vaultLock() error {
+	if err := sessionClient().ClearToken(); err != nil {
+		return err
+	}
+	logEvent(eventlog.Info, eventlog.TypeToken, "Vault locked", eventlog.Fields{"expires_in_s": 0})
+	recordAudit(audit.TokenClear, "session", map[string]string{"via": "vault lock"})
+	fmt.Println("Session server token cleared; unlock the vault in the TUI to load it again")
+	return nil
+}
+
+// vaultWipe destroys the vault file and clears the session server.
+func vaultWipe(yes bool) error {
+	path := profiles.VaultPath()
+	if !vault.Exists(path) {
+		return fmt.Errorf("no vault at %s", path)
+	}
+	if !yes && !confirm("Permanently destroy "+path+" and every token in it?") {
+		return errors.New("aborted")
+	}
+	if err := vault.Wipe(path); err != nil {
+		return err
+	}
+	logEvent(eventlog.Warn, eventlog.TypeToken, "Vault wiped", eventlog.Fields{"expires_in_s": 0})
+	recordAudit(audit.TokenClear, "vault:"+path, map[string]string{"via": "vault wipe"})
+	if err := sessionClient().ClearToken(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not clear session server token:", err)
+	}
+	fmt.Println("Vault wiped")
+	return nil
+}
+
+// readPassphrase reads a passphrase without echo, or from the environment
+// when set.
+func readPassphrase(prompt string) ([]byte, error) {
+	if v := os.Getenv(passphraseEnv); v != "" {
+		redact.AddSecret(v)
+		return []byte(v), nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil, fmt.Errorf("no terminal to read the passphrase from; set %s", passphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err == nil && len(p) == 0 {
+		err = errors.New("empty passphrase")
+	}
+	return p, err
+}
+
+func confirm(question string) bool {
+	fmt.Fprint(os.Stderr, question+" [y/N] ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(line), "y")
+}
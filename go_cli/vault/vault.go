@@ -0,0 +1,275 @@
+// Package vault encrypts small secrets, such as token profiles, under a
+// passphrase. Keys are derived with scrypt and data is sealed with
+// AES-256-GCM; the file on disk never holds plaintext, is written with mode
+// 0600 and is refused when other users can access it.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	version = 1
+	kdfName = "scrypt"
+	keyLen  = 32
+	saltLen = 16
+	// fileMode is enforced on every read and write.
+	fileMode = 0o600
+
+	// Limits on the scrypt parameters read from a vault file, so a crafted
+	// file cannot make unlocking take unbounded time or memory. The
+	// defaults are well inside them.
+	maxN      = 1 << 20
+	maxRP     = 64
+	maxMemory = 1 << 30
+)
+
+// ErrWrongPassphrase is returned when a vault cannot be decrypted, either
+// because the passphrase is wrong or the file was tampered with.
+var ErrWrongPassphrase = errors.New("vault: wrong passphrase or corrupted vault")
+
+// ErrInsecureMode is returned when others can access a vault file.
+var ErrInsecureMode = errors.New("vault: file is accessible by other users")
+
+// Params are the scrypt cost parameters.
+type Params struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// DefaultParams takes roughly 100ms and 32 MiB on a current machine.
+var DefaultParams = Params{N: 1 << 15, R: 8, P: 1}
+
+// validate rejects parameters scrypt cannot use or that would cost more
+// than the limits: N a power of two up to 2^20, r·p at most 64 and
+// 128·N·r bytes of memory at most 1 GiB.
+func (p Params) validate() error {
+	switch {
+	case p.N <= 1 || p.N&(p.N-1) != 0 || p.N > maxN,
+		p.R < 1 || p.P < 1 || p.R > maxRP || p.P > maxRP || p.R*p.P > maxRP,
+		128*p.N*p.R > maxMemory:
+		return fmt.Errorf("vault: unsupported scrypt parameters N=%d r=%d p=%d", p.N, p.R, p.P)
+	}
+	return nil
+}
+
+// Key is a passphrase-derived key together with the salt and parameters it
+// was derived with, so data can be re-sealed without the passphrase.
+type Key struct {
+	key    []byte
+	salt   []byte
+	params Params
+}
+
+// NewKey derives a key for a new vault from passphrase with a fresh salt.
+func NewKey(passphrase []byte) (*Key, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return deriveKey(passphrase, salt, DefaultParams)
+}
+
+func deriveKey(passphrase, salt []byte, p Params) (*Key, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("vault: empty passphrase")
+	}
+	k, err := scrypt.Key(passphrase, salt, p.N, p.R, p.P, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{key: k, salt: salt, params: p}, nil
+}
+
+// Wipe zeroes the key material.
+func (k *Key) Wipe() {
+	if k == nil {
+		return
+	}
+	clear(k.key)
+	k.key = nil
+}
+
+// file is the on-disk layout. Everything but the ciphertext is public and
+// authenticated as additional data.
+type file struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Params     Params `json:"params"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (f *file) additionalData() []byte {
+	return fmt.Appendf(nil, "nexa-vault:%d:%s:%d:%d:%d:%x", f.Version, f.KDF, f.Params.N, f.Params.R, f.Params.P, f.Salt)
+}
+
+// Seal encrypts plaintext under k and returns the vault file contents.
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	if k == nil || k.key == nil {
+		return nil, errors.New("vault: key has been wiped")
+	}
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+	f := file{Version: version, KDF: kdfName, Params: k.params, Salt: k.salt, Nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return nil, err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plaintext, f.additionalData())
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// Open decrypts vault file contents sealed under k.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	if k == nil || k.key == nil {
+		return nil, errors.New("vault: key has been wiped")
+	}
+	f, err := parseFile(data)
+	if err != nil {
+		return nil, err
+	}
+	return k.open(f)
+}
+
+// Unseal decrypts vault file contents with passphrase. It returns the key,
+// for re-sealing later changes, and the plaintext.
+func Unseal(data, passphrase []byte) (*Key, []byte, error) {
+	f, err := parseFile(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	k, err := deriveKey(passphrase, f.Salt, f.Params)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := k.open(f)
+	if err != nil {
+		k.Wipe()
+		return nil, nil, err
+	}
+	return k, plaintext, nil
+}
+
+func parseFile(data []byte) (*file, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	if f.Version != version || f.KDF != kdfName {
+		return nil, fmt.Errorf("vault: unsupported format %d/%s", f.Version, f.KDF)
+	}
+	if err := f.Params.validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (k *Key) open(f *file) ([]byte, error) {
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, f.Nonce, f.Ciphertext, f.additionalData())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Exists reports whether a vault file is present at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ReadFile reads a vault file. A file that group or other may access is
+// rejected with ErrInsecureMode rather than used.
+func ReadFile(path string) ([]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode().Perm()&^fileMode != 0 {
+		return nil, fmt.Errorf("%w: %s has mode %s; run chmod 600 on it", ErrInsecureMode, path, fi.Mode().Perm())
+	}
+	return os.ReadFile(path)
+}
+
+// WriteFile atomically replaces the vault file at path with data, mode 0600.
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Wipe overwrites the file at path with random bytes before removing it.
+// A missing file is not an error.
+func Wipe(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err == nil {
+		_, err = io.CopyN(f, rand.Reader, fi.Size())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
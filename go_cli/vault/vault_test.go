@@ -0,0 +1,197 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testParams keep the tests fast; they are not meant to resist guessing.
+var testParams = Params{N: 1 << 10, R: 8, P: 1}
+
+func sealed(t *testing.T, passphrase, plaintext string) (*Key, []byte) {
+	t.Helper()
+	k, err := deriveKey([]byte(passphrase), bytes.Repeat([]byte{7}, saltLen), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := k.Seal([]byte(plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k, data
+}
+
+// edit rewrites one field of a sealed vault file.
+func edit(t *testing.T, data []byte, fn func(f *file)) []byte {
+	t.Helper()
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	fn(&f)
+	out, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSealUnseal(t *testing.T) {
+	k, data := sealed(t, "correct horse", `{"profiles": []}`)
+	if bytes.Contains(data, []byte("profiles")) {
+		t.Fatalf("vault file holds plaintext: %s", data)
+	}
+	k2, plaintext, err := Unseal(data, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != `{"profiles": []}` {
+		t.Errorf("Unseal() = %q", plaintext)
+	}
+
+	// The returned key re-seals without the passphrase, and the original
+	// key opens the result.
+	resealed, err := k2.Seal([]byte("changed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := k.Open(resealed); err != nil || string(got) != "changed" {
+		t.Errorf("Open() = %q, %v", got, err)
+	}
+
+	k.Wipe()
+	if _, err := k.Seal([]byte("x")); err == nil {
+		t.Error("Seal() with a wiped key succeeded")
+	}
+	if _, err := k.Open(data); err == nil {
+		t.Error("Open() with a wiped key succeeded")
+	}
+}
+
+func TestUnsealRejects(t *testing.T) {
+	k, data := sealed(t, "correct horse", "secret")
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       error
+	}{
+		{
+			name:       "wrong passphrase",
+			data:       data,
+			passphrase: "battery staple",
+			want:       ErrWrongPassphrase,
+		},
+		{
+			name:       "tampered ciphertext",
+			data:       edit(t, data, func(f *file) { f.Ciphertext[0] ^= 1 }),
+			passphrase: "correct horse",
+			want:       ErrWrongPassphrase,
+		},
+		{
+			name:       "truncated nonce",
+			data:       edit(t, data, func(f *file) { f.Nonce = f.Nonce[:4] }),
+			passphrase: "correct horse",
+			want:       ErrWrongPassphrase,
+		},
+		{
+			name:       "empty passphrase",
+			data:       data,
+			passphrase: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Unseal(tt.data, []byte(tt.passphrase))
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Unseal() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// The metadata is authenticated: opening with the right key fails once
+	// any of it changes.
+	metadata := map[string]func(f *file){
+		"salt":   func(f *file) { f.Salt[0] ^= 1 },
+		"params": func(f *file) { f.Params.N *= 2 },
+	}
+	for name, fn := range metadata {
+		if _, err := k.Open(edit(t, data, fn)); !errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("Open() with tampered %s: %v, want ErrWrongPassphrase", name, err)
+		}
+	}
+}
+
+func TestUnsealParamLimits(t *testing.T) {
+	_, data := sealed(t, "correct horse", "secret")
+	tests := []Params{
+		{N: 1 << 30, R: 8, P: 1},
+		{N: 1000, R: 8, P: 1},
+		{N: 1, R: 8, P: 1},
+		{N: 1 << 10, R: 0, P: 1},
+		{N: 1 << 10, R: 8, P: 16},
+		{N: 1 << 20, R: 16, P: 1},
+		{N: 1 << 10, R: -8, P: -1},
+	}
+	for _, p := range tests {
+		_, _, err := Unseal(edit(t, data, func(f *file) { f.Params = p }), []byte("correct horse"))
+		if err == nil || errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("Unseal() with %+v: %v, want a parameter error", p, err)
+		}
+	}
+	if err := DefaultParams.validate(); err != nil {
+		t.Errorf("DefaultParams: %v", err)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles", "vault.json")
+	if err := WriteFile(path, []byte("sealed")); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("WriteFile mode = %s, want 0600", fi.Mode().Perm())
+	}
+	if data, err := ReadFile(path); err != nil || string(data) != "sealed" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+
+	for _, mode := range []os.FileMode{0o644, 0o640, 0o606} {
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadFile(path); !errors.Is(err, ErrInsecureMode) {
+			t.Errorf("ReadFile() of a %s file: %v, want ErrInsecureMode", mode, err)
+		}
+	}
+	if err := os.Chmod(path, 0o400); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(path); err != nil {
+		t.Errorf("ReadFile() of a read-only file: %v", err)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	if err := WriteFile(path, []byte("sealed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Wipe(path); err != nil {
+		t.Fatal(err)
+	}
+	if Exists(path) {
+		t.Error("vault still exists after Wipe")
+	}
+	if err := Wipe(path); err != nil {
+		t.Errorf("Wipe() of a missing vault: %v", err)
+	}
+}
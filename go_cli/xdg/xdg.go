@@ -0,0 +1,37 @@
+// Package xdg resolves the per-user directories Nexa stores its files in,
+// following the XDG Base Directory specification.
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appName is the subdirectory used inside each base directory.
+const appName = "nexa"
+
+// ConfigHome returns $XDG_CONFIG_HOME/nexa, defaulting to ~/.config/nexa.
+func ConfigHome() string {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataHome returns $XDG_DATA_HOME/nexa, defaulting to ~/.local/share/nexa.
+func DataHome() string {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateHome returns $XDG_STATE_HOME/nexa, defaulting to ~/.local/state/nexa.
+func StateHome() string {
+	return baseDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func baseDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, fallback, appName)
+}
@@ -111,7 +111,21 @@ async def global_exception_handler(exc: Exception):
     logger.error(f"Unhandled error: {exc}")
     return JSONResponse(status_code=500, content={"error": str(exc)})
 
-if __name__ == "__main__":
+# Set NEXA_SESSION_SOCKET to a path to listen on a Unix domain socket, readable
+# only by the current user, instead of the network.
+SESSION_SOCKET = os.environ.get("NEXA_SESSION_SOCKET")
+
+def serve_socket(path: str):
+    import uvicorn
+    from unix_socket import bind_private
+    sock = bind_private(path)
+    logger.info(f"Listening on unix://{path}")
+    uvicorn.run("session_server:app", fd=sock.fileno(), reload=False)
+
+if __name__ == "__main__" and SESSION_SOCKET:
+    logger.info("Starting Nexa Auto Session Manager...")
+    serve_socket(SESSION_SOCKET)
+elif __name__ == "__main__":
     logger.info("Starting Nexa Auto Session Manager...")
     sock = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
     try:
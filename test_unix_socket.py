@@ -0,0 +1,64 @@
+import os
+import socket
+import stat
+import tempfile
+import unittest
+
+from unix_socket import bind_private
+
+
+class BindPrivateTest(unittest.TestCase):
+    def setUp(self):
+        self.dir = tempfile.TemporaryDirectory()
+        self.path = os.path.join(self.dir.name, "nexa.sock")
+
+    def tearDown(self):
+        self.dir.cleanup()
+
+    def assert_private_socket(self):
+        mode = os.stat(self.path).st_mode
+        self.assertTrue(stat.S_ISSOCK(mode))
+        self.assertEqual(stat.S_IMODE(mode), 0o600)
+
+    def test_bound_socket_is_owner_only(self):
+        old_umask = os.umask(0o000)
+        try:
+            sock = bind_private(self.path)
+        finally:
+            os.umask(old_umask)
+        self.addCleanup(sock.close)
+        self.assert_private_socket()
+
+    def test_umask_is_restored(self):
+        old_umask = os.umask(0o022)
+        try:
+            bind_private(self.path).close()
+            self.assertEqual(os.umask(0o022), 0o022)
+        finally:
+            os.umask(old_umask)
+
+    def test_accepts_connections(self):
+        sock = bind_private(self.path)
+        self.addCleanup(sock.close)
+        sock.listen(1)
+        client = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+        self.addCleanup(client.close)
+        client.connect(self.path)
+
+    def test_replaces_stale_socket(self):
+        bind_private(self.path).close()
+        sock = bind_private(self.path)
+        self.addCleanup(sock.close)
+        self.assert_private_socket()
+
+    def test_refuses_to_replace_other_files(self):
+        with open(self.path, "w") as f:
+            f.write("not a socket")
+        with self.assertRaises(FileExistsError):
+            bind_private(self.path)
+        with open(self.path) as f:
+            self.assertEqual(f.read(), "not a socket")
+
+
+if __name__ == "__main__":
+    unittest.main()
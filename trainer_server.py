@@ -1,4 +1,7 @@
+import http.client
+import json
 import os
+import socket
 import sys
 import time
 import uuid
@@ -26,13 +29,40 @@ logging.basicConfig(
 logger = logging.getLogger(__name__)
 
 jobs = {}
-SESSION_SERVER_URL = "http://127.0.0.1:8765"
+# Either a base URL or unix:///path/to/socket, matching the Go client's config.
+SESSION_SERVER_URL = os.environ.get("NEXA_SESSION_URL", "http://127.0.0.1:8765")
+# Set NEXA_TRAINER_SOCKET to listen on a Unix domain socket instead of TCP.
+TRAINER_SOCKET = os.environ.get("NEXA_TRAINER_SOCKET")
+
+class UnixHTTPConnection(http.client.HTTPConnection):
+    def __init__(self, path: str, timeout: float = 5):
+        super().__init__("localhost", timeout=timeout)
+        self.unix_path = path
+
+    def connect(self):
+        self.sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+        self.sock.settimeout(self.timeout)
+        self.sock.connect(self.unix_path)
+
+def session_get(path: str, timeout: float = 5):
+    """GET path from the session server over TCP or its Unix socket and
+    return (status code, decoded JSON body)."""
+    if SESSION_SERVER_URL.startswith("unix://"):
+        conn = UnixHTTPConnection(SESSION_SERVER_URL[len("unix://"):], timeout=timeout)
+        try:
+            conn.request("GET", path)
+            resp = conn.getresponse()
+            return resp.status, json.loads(resp.read() or b"null")
+        finally:
+            conn.close()
+    resp = requests.get(f"{SESSION_SERVER_URL}{path}", timeout=timeout)
+    return resp.status_code, resp.json()
 
 def get_token():
     try:
-        resp = requests.get(f"{SESSION_SERVER_URL}/get_token")
-        if resp.status_code == 200:
-            return resp.json()["token"]
+        status, body = session_get("/get_token")
+        if status == 200:
+            return body["token"]
     except Exception:
         pass
     return None
@@ -125,8 +155,8 @@ def get_status(job_id: str):
 @app.get("/health")
 def health():
     try:
-        session_response = requests.get(f"{SESSION_SERVER_URL}/health", timeout=2)
-        session_status = session_response.ok
+        status, _ = session_get("/health", timeout=2)
+        session_status = 200 <= status < 300
     except Exception as e:
         logger.error(f"Session server health check failed: {e}")
         session_status = False
@@ -143,6 +173,12 @@ def health():
 if __name__ == "__main__":
     logger.info("Starting trainer server...")
     try:
-        uvicorn.run("trainer_server:app", host="0.0.0.0", port=8770, reload=False)
+        if TRAINER_SOCKET:
+            from unix_socket import bind_private
+            sock = bind_private(TRAINER_SOCKET)
+            logger.info(f"Listening on unix://{TRAINER_SOCKET}")
+            uvicorn.run("trainer_server:app", fd=sock.fileno(), reload=False)
+        else:
+            uvicorn.run("trainer_server:app", host="0.0.0.0", port=8770, reload=False)
     except Exception as e:
         logger.error(f"Failed to start trainer server: {e}")
@@ -0,0 +1,38 @@
+"""Unix domain sockets that only the current user can connect to.
+
+uvicorn chmods a socket it binds itself to 0666, so the servers bind their
+socket here and hand uvicorn the file descriptor instead.
+"""
+import os
+import socket
+import stat
+
+
+def bind_private(path: str) -> socket.socket:
+    """Binds a Unix stream socket at path that only the owner may connect to.
+
+    A stale socket left at path by a previous run is replaced; any other kind
+    of file is left alone and raises FileExistsError. The socket is created
+    under a 0177 umask, so it is never reachable by other users, and its mode
+    is then set to 0600 explicitly.
+    """
+    try:
+        mode = os.lstat(path).st_mode
+    except FileNotFoundError:
+        pass
+    else:
+        if not stat.S_ISSOCK(mode):
+            raise FileExistsError(f"{path} exists and is not a socket")
+        os.remove(path)
+
+    sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+    old_umask = os.umask(0o177)
+    try:
+        sock.bind(path)
+    except OSError:
+        sock.close()
+        raise
+    finally:
+        os.umask(old_umask)
+    os.chmod(path, 0o600)
+    return sock